@@ -0,0 +1,90 @@
+package admission
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrExhausted is returned when no slot was available before queue timeout
+var ErrExhausted = status.Error(codes.ResourceExhausted, "Too many requests in flight")
+
+// Limiter bounds number of concurrent calls. Calls which cannot run
+// immediately wait in a bounded queue until a slot is released.
+type Limiter struct {
+	name     string
+	slots    chan struct{}
+	maxQueue int
+	timeout  time.Duration
+
+	mutex  sync.Mutex
+	queued int
+}
+
+func CreateLimiter(name string, maxInFlight int, maxQueue int, timeout time.Duration) *Limiter {
+	return &Limiter{
+		name:     name,
+		slots:    make(chan struct{}, maxInFlight),
+		maxQueue: maxQueue,
+		timeout:  timeout,
+	}
+}
+
+func (l *Limiter) Name() string {
+	return l.name
+}
+
+func (l *Limiter) InFlight() int {
+	return len(l.slots)
+}
+
+func (l *Limiter) Queued() int {
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.queued
+}
+
+func (l *Limiter) Acquire(ctx context.Context) error {
+
+	// Fast path
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	l.mutex.Lock()
+	if l.queued >= l.maxQueue {
+		l.mutex.Unlock()
+		return ErrExhausted
+	}
+	l.queued++
+	l.mutex.Unlock()
+
+	defer func() {
+		l.mutex.Lock()
+		l.queued--
+		l.mutex.Unlock()
+	}()
+
+	timer := time.NewTimer(l.timeout)
+	defer timer.Stop()
+
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-timer.C:
+		return ErrExhausted
+	case <-ctx.Done():
+		return ErrExhausted
+	}
+}
+
+func (l *Limiter) Release() {
+	<-l.slots
+}
@@ -0,0 +1,117 @@
+package admission
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+type Options struct {
+	MaxInFlight  int
+	MaxQueue     int
+	QueueTimeout time.Duration
+}
+
+// Controller applies global limit and per-operation limits to every call.
+// Zero limit means unlimited.
+type Controller struct {
+	global     *Limiter
+	operations map[string]*Limiter
+	retryAfter time.Duration
+}
+
+func CreateController(global Options, retryAfter time.Duration) *Controller {
+
+	if retryAfter <= 0 {
+		retryAfter = time.Second
+	}
+
+	c := &Controller{
+		operations: make(map[string]*Limiter),
+		retryAfter: retryAfter,
+	}
+
+	if global.MaxInFlight > 0 {
+		c.global = CreateLimiter("global", global.MaxInFlight, global.MaxQueue, global.QueueTimeout)
+	}
+
+	return c
+}
+
+func (c *Controller) SetOperationLimit(operation string, opts Options) {
+
+	if opts.MaxInFlight <= 0 {
+		delete(c.operations, operation)
+		return
+	}
+
+	c.operations[operation] = CreateLimiter(operation, opts.MaxInFlight, opts.MaxQueue, opts.QueueTimeout)
+}
+
+// RetryAfter is a hint for clients which were rejected
+func (c *Controller) RetryAfter() time.Duration {
+	return c.retryAfter
+}
+
+// Limiters returns all limiters for reporting occupancy
+func (c *Controller) Limiters() []*Limiter {
+
+	limiters := make([]*Limiter, 0, len(c.operations)+1)
+
+	if c.global != nil {
+		limiters = append(limiters, c.global)
+	}
+
+	for _, l := range c.operations {
+		limiters = append(limiters, l)
+	}
+
+	return limiters
+}
+
+// Reserve takes a slot of operation only, without waiting. It bounds resources
+// which are held by calls owning a global slot already.
+func (c *Controller) Reserve(operation string) (func(), error) {
+
+	limiter := c.operations[operation]
+	if limiter == nil {
+		return func() {}, nil
+	}
+
+	if err := limiter.Acquire(context.Background()); err != nil {
+		return nil, err
+	}
+
+	return limiter.Release, nil
+}
+
+// Acquire takes a slot of operation and a global slot. Returned function must
+// be called to release them.
+func (c *Controller) Acquire(ctx context.Context, operation string) (func(), error) {
+
+	opLimiter := c.operations[operation]
+	if opLimiter != nil {
+		if err := opLimiter.Acquire(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.global != nil {
+		if err := c.global.Acquire(ctx); err != nil {
+			if opLimiter != nil {
+				opLimiter.Release()
+			}
+			return nil, err
+		}
+	}
+
+	return func() {
+		if c.global != nil {
+			c.global.Release()
+		}
+
+		if opLimiter != nil {
+			opLimiter.Release()
+		}
+	}, nil
+}
@@ -0,0 +1,37 @@
+package admission
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestReserveDoesNotTakeGlobalSlot(t *testing.T) {
+
+	c := CreateController(Options{MaxInFlight: 1, QueueTimeout: time.Millisecond}, time.Second)
+	c.SetOperationLimit("agents", Options{MaxInFlight: 1})
+
+	release, err := c.Acquire(context.Background(), "create")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	// Call which owns the global slot can still reserve an agent
+	releaseAgent, err := c.Reserve("agents")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Limit of agents is not waited for
+	if _, err := c.Reserve("agents"); err != ErrExhausted {
+		t.Fatalf("expected ErrExhausted, got %v", err)
+	}
+
+	releaseAgent()
+
+	if _, err := c.Reserve("agents"); err != nil {
+		t.Fatalf("released slot is not available: %v", err)
+	}
+}
@@ -1,9 +1,23 @@
 package app
 
 import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
 	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+	"twist-commander/app/admission"
+	"twist-commander/app/breaker"
+	"twist-commander/app/codec"
 	app "twist-commander/app/interface"
+	"twist-commander/app/ratelimit"
 	"twist-commander/app/signalbus"
+	"twist-commander/app/store"
+	"twist-commander/app/subject"
+	"twist-commander/app/supervisor"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/soheilhy/cmux"
@@ -14,9 +28,25 @@ import (
 type App struct {
 	id                 uint64
 	flake              *sonyflake.Sonyflake
-	signalbus          *signalbus.SignalBus
+	signalbus          signalbus.Bus
+	embeddedServer     *signalbus.EmbeddedServer
+	subjects           *subject.Builder
+	codec              *codec.Codec
+	store              app.TransactionStore
+	supervisor         app.SupervisorClient
+	breakers           []*breaker.Breaker
+	admission          *admission.Controller
+	rateLimiter        *ratelimit.RateLimiter
+	listener           net.Listener
 	connectionListener cmux.CMux
 	grpcServer         *GRPCServer
+	httpServer         *http.Server
+	port               int
+	drainTimeout       time.Duration
+	shuttingDown       int32
+	config             atomic.Value
+	configVersion      int64
+	configMutex        sync.Mutex
 }
 
 func CreateApp() *App {
@@ -28,17 +58,67 @@ func CreateApp() *App {
 		return nil
 	}
 
-	idStr := strconv.FormatUint(id, 16)
-
 	return &App{
-		id:    id,
-		flake: flake,
-		signalbus: signalbus.CreateConnector(
-			viper.GetString("signal_server.host"),
-			idStr,
-		),
-		grpcServer: &GRPCServer{},
+		id:           id,
+		flake:        flake,
+		breakers:     []*breaker.Breaker{},
+		admission:    createAdmissionController(),
+		rateLimiter:  ratelimit.CreateRateLimiter(viper.GetDuration("rate_limit.idle_timeout")),
+		grpcServer:   &GRPCServer{},
+		port:         viper.GetInt("service.port"),
+		drainTimeout: viper.GetDuration("service.drain_timeout"),
+	}
+}
+
+func createAdmissionController() *admission.Controller {
+
+	global := admission.Options{
+		MaxInFlight:  viper.GetInt("admission.max_in_flight"),
+		MaxQueue:     viper.GetInt("admission.max_queue"),
+		QueueTimeout: viper.GetDuration("admission.queue_timeout"),
+	}
+
+	c := admission.CreateController(global, viper.GetDuration("admission.retry_after"))
+
+	// Operations inherit queue settings unless they were specified
+	for _, operation := range []string{"create", "confirm", "cancel", "tasks"} {
+
+		opts := global
+		opts.MaxInFlight = viper.GetInt("admission.operations." + operation + ".max_in_flight")
+
+		if viper.IsSet("admission.operations." + operation + ".max_queue") {
+			opts.MaxQueue = viper.GetInt("admission.operations." + operation + ".max_queue")
+		}
+
+		if viper.IsSet("admission.operations." + operation + ".queue_timeout") {
+			opts.QueueTimeout = viper.GetDuration("admission.operations." + operation + ".queue_timeout")
+		}
+
+		c.SetOperationLimit(operation, opts)
 	}
+
+	// Agents wait for events of runner, so they share the global limit and are
+	// rejected immediately once it was reached
+	agents := admission.Options{
+		MaxInFlight: global.MaxInFlight,
+	}
+
+	if viper.IsSet("admission.operations.agents.max_in_flight") {
+		agents.MaxInFlight = viper.GetInt("admission.operations.agents.max_in_flight")
+	}
+
+	c.SetOperationLimit("agents", agents)
+
+	return c
+}
+
+func createBreaker(name string) *breaker.Breaker {
+	return breaker.CreateBreaker(
+		name,
+		viper.GetInt("circuit_breaker.failure_threshold"),
+		viper.GetDuration("circuit_breaker.open_timeout"),
+		viper.GetInt("circuit_breaker.half_open_probes"),
+	)
 }
 
 func (a *App) Init() error {
@@ -47,29 +127,355 @@ func (a *App) Init() error {
 		"a_id": a.id,
 	}).Info("Starting application")
 
+	// Subjects are scoped by environment and tenant
+	subjects, err := subject.CreateBuilder(
+		viper.GetString("signal_server.subject_prefix"),
+		viper.GetString("signal_server.tenant"),
+	)
+	if err != nil {
+		return err
+	}
+
+	a.subjects = subjects
+
+	// Encoding of commands, events are decoded whatever encoding they have
+	c, err := codec.CreateCodec(viper.GetString("signal_server.encoding"), a.GetInstanceID())
+	if err != nil {
+		return err
+	}
+
+	a.codec = c
+
+	// Transactions are recorded for status queries, recovery and auditing
+	ts, err := createStore(viper.GetString("store.backend"))
+	if err != nil {
+		return err
+	}
+
+	a.store = ts
+
+	transport := viper.GetString("signal_server.transport")
+
+	err = validateTransport(
+		transport,
+		viper.GetBool("signal_server.embedded.enabled"),
+		viper.GetString("signal_server.streaming.client_id"),
+	)
+	if err != nil {
+		return err
+	}
+
+	// Runners connect to signal server which is embedded in commander
+	if viper.GetBool("signal_server.embedded.enabled") {
+
+		a.embeddedServer = signalbus.CreateEmbeddedServer(signalbus.EmbeddedOptions{
+			Host:         viper.GetString("signal_server.embedded.host"),
+			Port:         viper.GetInt("signal_server.embedded.port"),
+			ClusterHost:  viper.GetString("signal_server.embedded.cluster_host"),
+			ClusterPort:  viper.GetInt("signal_server.embedded.cluster_port"),
+			Routes:       viper.GetStringSlice("signal_server.embedded.routes"),
+			StartTimeout: viper.GetDuration("signal_server.embedded.start_timeout"),
+		})
+
+		err := a.embeddedServer.Start()
+		if err != nil {
+			return err
+		}
+	}
+
 	// Connect to signal server
-	err := a.signalbus.Connect()
+	sb, err := a.createSignalBus(transport)
+	if err != nil {
+		return err
+	}
+
+	a.signalbus = sb
+
+	err = a.signalbus.Connect()
+	if err != nil {
+		return err
+	}
+
+	// Connect to supervisor
+	client, err := a.createSupervisorClient(viper.GetString("supervisor.protocol"))
+	if err != nil {
+		return err
+	}
+
+	supervisorBreaker := createBreaker("supervisor")
+	a.breakers = append(a.breakers, supervisorBreaker)
+	a.supervisor = supervisor.CreateBreakerClient(client, supervisorBreaker)
+
+	err = a.supervisor.Connect()
+	if err != nil {
+		return err
+	}
+
+	// Settings which can be reloaded at runtime
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	err = a.applyConfig(cfg)
 	if err != nil {
 		return err
 	}
 
+	// Servers read settings while being created, which must not happen while
+	// configuration is reloaded
+	a.InitGRPCServer()
+	a.InitHTTPServer()
+
+	a.watchConfig()
+
+	return nil
+}
+
+func createStore(backend string) (app.TransactionStore, error) {
+
+	switch backend {
+	case "", store.BackendMemory:
+		return store.CreateMemoryStore(
+			viper.GetDuration("store.retention"),
+			viper.GetDuration("store.abandoned_retention"),
+		), nil
+	case store.BackendFile:
+		fs := store.CreateFileStore(
+			viper.GetString("store.path"),
+			viper.GetDuration("store.retention"),
+			viper.GetDuration("store.abandoned_retention"),
+		)
+
+		err := fs.Open()
+		if err != nil {
+			return nil, err
+		}
+
+		return fs, nil
+	}
+
+	return nil, errors.New("Unsupported transaction store: " + backend)
+}
+
+// validateTransport rejects settings which cannot work together. Embedded
+// server is a plain NATS server, which neither provides streaming nor is used
+// by in-memory bus.
+func validateTransport(transport string, embedded bool, clientID string) error {
+
+	// Events of instance would be lost whenever it restarts
+	if transport == signalbus.TransportStreaming && clientID == "" {
+		return errors.New("Streaming transport requires signal_server.streaming.client_id, which is stable across restarts")
+	}
+
+	if !embedded {
+		return nil
+	}
+
+	switch transport {
+	case signalbus.TransportStreaming:
+		return errors.New("Embedded signal server does not support streaming transport, use external NATS Streaming server")
+	case signalbus.TransportMemory:
+		return errors.New("Embedded signal server cannot be used with memory transport")
+	}
+
 	return nil
 }
 
+func (a *App) createSignalBus(transport string) (signalbus.Bus, error) {
+
+	opts := signalbus.Options{
+		MaxReconnects:    -1,
+		ReconnectWait:    viper.GetDuration("signal_server.reconnect_wait"),
+		ReconnectBufSize: viper.GetInt("signal_server.reconnect_buffer_size"),
+		OutageGrace:      viper.GetDuration("signal_server.outage_grace"),
+		LeakThreshold:    viper.GetDuration("signal_server.subscription_leak_threshold"),
+	}
+
+	if viper.IsSet("signal_server.max_reconnects") {
+		opts.MaxReconnects = viper.GetInt("signal_server.max_reconnects")
+	}
+
+	switch transport {
+	case "", signalbus.TransportNATS, signalbus.TransportStreaming:
+
+		signalBusBreaker := createBreaker("signalbus")
+		a.breakers = append(a.breakers, signalBusBreaker)
+
+		host := viper.GetString("signal_server.host")
+		if a.embeddedServer != nil {
+			host = a.embeddedServer.ClientURL()
+		}
+
+		sb := signalbus.CreateConnector(
+			host,
+			a.GetInstanceID(),
+			signalBusBreaker,
+			opts,
+		)
+
+		if transport != signalbus.TransportStreaming {
+			return sb, nil
+		}
+
+		channel := viper.GetString("signal_server.streaming.channel")
+		if channel == "" {
+			channel = a.subjects.Namespace() + ".signals"
+		}
+
+		// Commands and events go through durable stream
+		return signalbus.CreateStreamingBus(sb, signalbus.StreamingOptions{
+			ClusterID:        viper.GetString("signal_server.streaming.cluster_id"),
+			ClientID:         viper.GetString("signal_server.streaming.client_id"),
+			Channel:          channel,
+			PubAckWait:       viper.GetDuration("signal_server.streaming.pub_ack_wait"),
+			AckWait:          viper.GetDuration("signal_server.streaming.ack_wait"),
+			MaxInflight:      viper.GetInt("signal_server.streaming.max_inflight"),
+			RedeliveryWindow: viper.GetDuration("signal_server.streaming.redelivery_window"),
+			PingInterval:     viper.GetInt("signal_server.streaming.ping_interval"),
+			PingMaxOut:       viper.GetInt("signal_server.streaming.ping_max_out"),
+		}), nil
+	case signalbus.TransportMemory:
+		return signalbus.CreateMemoryBus(opts), nil
+	}
+
+	return nil, errors.New("Unsupported signal bus transport: " + transport)
+}
+
+func (a *App) createSupervisorClient(protocol string) (app.SupervisorClient, error) {
+
+	switch protocol {
+	case "", "grpc":
+
+		// Multiple supervisors can be specified
+		hosts := viper.GetStringSlice("supervisor.hosts")
+		if len(hosts) == 0 {
+			hosts = []string{viper.GetString("supervisor.host")}
+		}
+
+		return supervisor.CreateGRPCClient(
+			hosts,
+			viper.GetString("supervisor.balancer"),
+			viper.GetBool("supervisor.health_check"),
+			viper.GetDuration("supervisor.timeout"),
+		), nil
+	case "nats":
+
+		// Subject is derived from namespace unless it was specified
+		topic := viper.GetString("supervisor.subject")
+		if topic == "" {
+			topic = a.subjects.Supervisor("prepareTransaction")
+		}
+
+		return supervisor.CreateNATSClient(
+			a.signalbus,
+			topic,
+			viper.GetDuration("supervisor.timeout"),
+		), nil
+	}
+
+	return nil, errors.New("Unsupported supervisor protocol: " + protocol)
+}
+
+func (a *App) isShuttingDown() bool {
+	return atomic.LoadInt32(&a.shuttingDown) == 1
+}
+
+// Uninit stops accepting new requests and waits for requests in flight to be
+// completed, until drain timeout is reached.
 func (a *App) Uninit() {
+
+	if !atomic.CompareAndSwapInt32(&a.shuttingDown, 0, 1) {
+		return
+	}
+
+	drainTimeout := a.drainTimeout
+	if drainTimeout <= 0 {
+		drainTimeout = 30 * time.Second
+	}
+
+	log.WithFields(log.Fields{
+		"timeout": drainTimeout,
+	}).Info("Shutting down application")
+
+	// Stop accepting new connections
+	if a.listener != nil {
+		a.listener.Close()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+
+		if a.httpServer != nil {
+			a.httpServer.Shutdown(ctx)
+		}
+
+		if a.grpcServer.server != nil {
+			a.grpcServer.server.GracefulStop()
+		}
+
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Warn("Drain timeout reached, aborting requests in flight")
+	}
+
+	// Requests which are still waiting for runner give up
+	if a.grpcServer.Commander != nil {
+		a.grpcServer.Commander.Close()
+	}
+
+	if a.grpcServer.server != nil {
+		a.grpcServer.server.Stop()
+	}
+
+	if a.httpServer != nil {
+		a.httpServer.Close()
+	}
+
+	if a.supervisor != nil {
+		a.supervisor.Close()
+	}
+
+	a.signalbus.Close()
+
+	if a.embeddedServer != nil {
+		a.embeddedServer.Shutdown()
+	}
+
+	if a.store != nil {
+		if err := a.store.Close(); err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+			}).Warn("Failed to close transaction store")
+		}
+	}
+
+	log.Info("Application was stopped")
 }
 
 func (a *App) Run() error {
 
-	port := strconv.Itoa(viper.GetInt("service.port"))
+	port := strconv.Itoa(a.port)
 	err := a.CreateConnectionListener(":" + port)
 	if err != nil {
 		return err
 	}
 
+	httpListener := a.connectionListener.Match(cmux.HTTP1Fast())
+	grpcListener := a.connectionListener.MatchWithWriters(
+		cmux.HTTP2MatchHeaderFieldSendSettings("content-type", "application/grpc"),
+	)
+
 	// HTTP
 	go func() {
-		err := a.InitHTTPServer(":" + port)
+		err := a.ServeHTTPServer(":"+port, httpListener)
 		if err != nil {
 			log.Error(err)
 		}
@@ -77,7 +483,7 @@ func (a *App) Run() error {
 
 	// gRPC
 	go func() {
-		err := a.InitGRPCServer(":" + port)
+		err := a.ServeGRPCServer(":"+port, grpcListener)
 		if err != nil {
 			log.Error(err)
 		}
@@ -94,3 +500,28 @@ func (a *App) Run() error {
 func (a *App) GetSignalBus() app.SignalBusImpl {
 	return app.SignalBusImpl(a.signalbus)
 }
+
+func (a *App) GetSupervisorClient() app.SupervisorClient {
+	return a.supervisor
+}
+
+func (a *App) GetAdmission() app.AdmissionImpl {
+	return app.AdmissionImpl(a.admission)
+}
+
+func (a *App) GetSubjects() app.SubjectImpl {
+	return app.SubjectImpl(a.subjects)
+}
+
+func (a *App) GetCodec() app.CodecImpl {
+	return app.CodecImpl(a.codec)
+}
+
+func (a *App) GetStore() app.TransactionStore {
+	return a.store
+}
+
+// GetInstanceID returns ID of this commander instance in hex form
+func (a *App) GetInstanceID() string {
+	return strconv.FormatUint(a.id, 16)
+}
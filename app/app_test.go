@@ -0,0 +1,58 @@
+package app
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"twist-commander/app/signalbus"
+)
+
+func TestValidateTransport(t *testing.T) {
+
+	cases := []struct {
+		transport string
+		embedded  bool
+		clientID  string
+		valid     bool
+	}{
+		{"", true, "", true},
+		{signalbus.TransportNATS, true, "", true},
+		{signalbus.TransportStreaming, true, "commander-1", false},
+		{signalbus.TransportMemory, true, "", false},
+		{signalbus.TransportStreaming, false, "commander-1", true},
+		{signalbus.TransportMemory, false, "", true},
+
+		// Durable subscription could not be resumed after restart
+		{signalbus.TransportStreaming, false, "", false},
+	}
+
+	for _, c := range cases {
+		err := validateTransport(c.transport, c.embedded, c.clientID)
+		if (err == nil) != c.valid {
+			t.Errorf("transport %q, embedded %v: unexpected result %v", c.transport, c.embedded, err)
+		}
+	}
+}
+
+func TestHTTPStatusFromError(t *testing.T) {
+
+	cases := []struct {
+		err    error
+		status int
+	}{
+		{status.Error(codes.InvalidArgument, "Invalid transaction ID"), http.StatusBadRequest},
+		{status.Error(codes.NotFound, "Task not found"), http.StatusNotFound},
+		{status.Error(codes.Unavailable, "Signal server is not available"), http.StatusServiceUnavailable},
+		{errors.New("Failed"), http.StatusInternalServerError},
+	}
+
+	for _, c := range cases {
+		if code := httpStatusFromError(c.err); code != c.status {
+			t.Errorf("%v: expected %d, got %d", c.err, c.status, code)
+		}
+	}
+}
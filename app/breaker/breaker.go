@@ -0,0 +1,211 @@
+package breaker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type State int
+
+const (
+	StateClosed State = iota
+	StateHalfOpen
+	StateOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateHalfOpen:
+		return "half-open"
+	case StateOpen:
+		return "open"
+	}
+
+	return "unknown"
+}
+
+// ErrOpen is returned without calling remote side while breaker is open
+var ErrOpen = status.Error(codes.Unavailable, "Circuit breaker is open")
+
+// IsFailure reports whether err means that dependency is degraded. Errors
+// caused by request itself, like invalid arguments, come from a healthy
+// dependency and do not count.
+func IsFailure(err error) bool {
+
+	if err == nil {
+		return false
+	}
+
+	if err == context.DeadlineExceeded {
+		return true
+	}
+
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+
+	return false
+}
+
+// Breaker stops calling a degraded dependency after a number of consecutive
+// failures. Once open timeout elapsed, a limited number of probes are allowed
+// to find out whether the dependency has recovered.
+type Breaker struct {
+	name             string
+	failureThreshold int
+	openTimeout      time.Duration
+	halfOpenProbes   int
+
+	mutex    sync.Mutex
+	state    State
+	failures int
+	probes   int
+	openedAt time.Time
+	rejected uint64
+}
+
+func CreateBreaker(name string, failureThreshold int, openTimeout time.Duration, halfOpenProbes int) *Breaker {
+
+	if failureThreshold <= 0 {
+		failureThreshold = 5
+	}
+
+	if openTimeout <= 0 {
+		openTimeout = 10 * time.Second
+	}
+
+	if halfOpenProbes <= 0 {
+		halfOpenProbes = 1
+	}
+
+	return &Breaker{
+		name:             name,
+		failureThreshold: failureThreshold,
+		openTimeout:      openTimeout,
+		halfOpenProbes:   halfOpenProbes,
+		state:            StateClosed,
+	}
+}
+
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+func (b *Breaker) State() State {
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.state
+}
+
+// Rejected returns number of calls which were rejected while breaker is open
+func (b *Breaker) Rejected() uint64 {
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.rejected
+}
+
+// Allow returns ErrOpen if the call should not be made. Every allowed call
+// must be followed by Success or Failure.
+func (b *Breaker) Allow() error {
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.state == StateOpen {
+		if time.Since(b.openedAt) < b.openTimeout {
+			b.rejected++
+			return ErrOpen
+		}
+
+		b.setState(StateHalfOpen)
+	}
+
+	if b.state == StateHalfOpen {
+		if b.probes >= b.halfOpenProbes {
+			b.rejected++
+			return ErrOpen
+		}
+
+		b.probes++
+	}
+
+	return nil
+}
+
+func (b *Breaker) Success() {
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.failures = 0
+
+	if b.state == StateHalfOpen {
+		b.setState(StateClosed)
+	}
+}
+
+func (b *Breaker) Failure() {
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.failures++
+
+	if b.state == StateHalfOpen || b.failures >= b.failureThreshold {
+		b.setState(StateOpen)
+	}
+}
+
+// Trip opens breaker without waiting for failures, for dependencies whose
+// outage is known from connection state
+func (b *Breaker) Trip() {
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.setState(StateOpen)
+}
+
+// Reset closes breaker once dependency is known to be back
+func (b *Breaker) Reset() {
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.setState(StateClosed)
+}
+
+func (b *Breaker) setState(state State) {
+
+	if b.state == state {
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"breaker": b.name,
+		"from":    b.state.String(),
+		"to":      state.String(),
+	}).Warn("Circuit breaker state changed")
+
+	b.state = state
+	b.probes = 0
+
+	switch state {
+	case StateOpen:
+		b.openedAt = time.Now()
+	case StateClosed:
+		b.failures = 0
+	}
+}
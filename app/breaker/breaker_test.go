@@ -0,0 +1,63 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIsFailure(t *testing.T) {
+
+	cases := []struct {
+		err     error
+		failure bool
+	}{
+		{nil, false},
+		{errors.New("Failed to parse reply"), false},
+		{status.Error(codes.InvalidArgument, "Invalid transaction ID"), false},
+		{status.Error(codes.NotFound, "Transaction not found"), false},
+		{status.Error(codes.Unavailable, "No healthy supervisor is available"), true},
+		{status.Error(codes.DeadlineExceeded, "Supervisor did not reply in time"), true},
+		{context.DeadlineExceeded, true},
+	}
+
+	for _, c := range cases {
+		if IsFailure(c.err) != c.failure {
+			t.Errorf("IsFailure(%v) should be %v", c.err, c.failure)
+		}
+	}
+}
+
+func TestBreakerOpensAfterFailures(t *testing.T) {
+
+	b := CreateBreaker("test", 2, time.Hour, 1)
+
+	b.Failure()
+	if b.Allow() != nil {
+		t.Fatal("breaker opened before threshold")
+	}
+
+	b.Failure()
+	if b.Allow() != ErrOpen {
+		t.Fatal("breaker did not open after threshold")
+	}
+}
+
+func TestBreakerTripAndReset(t *testing.T) {
+
+	b := CreateBreaker("test", 5, time.Hour, 1)
+
+	b.Trip()
+	if b.State() != StateOpen || b.Allow() != ErrOpen {
+		t.Fatal("tripped breaker is not open")
+	}
+
+	b.Reset()
+	if b.State() != StateClosed || b.Allow() != nil {
+		t.Fatal("reset breaker is not closed")
+	}
+}
@@ -0,0 +1,145 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	uuid "github.com/satori/go.uuid"
+	log "github.com/sirupsen/logrus"
+
+	pb "twist-commander/pb"
+)
+
+// Encodings of messages. Bare protobuf has no envelope, which runners
+// predating envelopes understand.
+const (
+	EncodingBare     = "bare"
+	EncodingProtobuf = "protobuf"
+	EncodingJSON     = "json"
+)
+
+// SchemaVersion of envelopes which are sent, receivers accept any minor
+// version of the same major version
+const (
+	SchemaVersion = "1.0"
+	MajorVersion  = 1
+)
+
+var (
+	ErrUnsupportedVersion = errors.New("Unsupported schema version")
+	ErrUnexpectedMessage  = errors.New("Unexpected message type")
+)
+
+// Codec wraps messages on signal bus into versioned envelope
+type Codec struct {
+	encoding string
+	source   string
+}
+
+func CreateCodec(encoding string, source string) (*Codec, error) {
+
+	switch encoding {
+	case "":
+		encoding = EncodingBare
+	case EncodingBare, EncodingProtobuf, EncodingJSON:
+	default:
+		return nil, errors.New("Unsupported encoding: " + encoding)
+	}
+
+	return &Codec{
+		encoding: encoding,
+		source:   source,
+	}, nil
+}
+
+func (c *Codec) Encode(msg proto.Message) ([]byte, error) {
+
+	if c.encoding == EncodingBare {
+		return proto.Marshal(msg)
+	}
+
+	message, err := ptypes.MarshalAny(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp, err := ptypes.TimestampProto(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	envelope := &pb.Envelope{
+		SchemaVersion: SchemaVersion,
+		Encoding:      c.encoding,
+		MessageID:     uuid.NewV4().String(),
+		Timestamp:     timestamp,
+		Source:        c.source,
+		Message:       message,
+	}
+
+	if c.encoding == EncodingJSON {
+		var buf bytes.Buffer
+		err := (&jsonpb.Marshaler{}).Marshal(&buf, envelope)
+		if err != nil {
+			return nil, err
+		}
+
+		return buf.Bytes(), nil
+	}
+
+	return proto.Marshal(envelope)
+}
+
+// Decode accepts envelopes of both encodings regardless of configured one, and
+// bare protobuf messages of senders which predate envelopes
+func (c *Codec) Decode(data []byte, msg proto.Message) error {
+
+	var envelope pb.Envelope
+
+	// Binary message might start with bytes which look like whitespace, while
+	// '{' would be a group field which none of messages has
+	if len(data) > 0 && data[0] == '{' {
+		err := (&jsonpb.Unmarshaler{AllowUnknownFields: true}).Unmarshal(bytes.NewReader(data), &envelope)
+		if err != nil {
+			return err
+		}
+	} else {
+		err := proto.Unmarshal(data, &envelope)
+		if err != nil {
+			return err
+		}
+
+		// Bare message has none of envelope fields
+		if envelope.SchemaVersion == "" {
+			return proto.Unmarshal(data, msg)
+		}
+	}
+
+	major, err := parseMajor(envelope.SchemaVersion)
+	if err != nil || major != MajorVersion {
+		log.WithFields(log.Fields{
+			"schemaVersion": envelope.SchemaVersion,
+			"supported":     MajorVersion,
+			"messageID":     envelope.MessageID,
+			"source":        envelope.Source,
+		}).Error("Rejected message with unsupported schema version")
+
+		return ErrUnsupportedVersion
+	}
+
+	if envelope.Message == nil || !ptypes.Is(envelope.Message, msg) {
+		return ErrUnexpectedMessage
+	}
+
+	return ptypes.UnmarshalAny(envelope.Message, msg)
+}
+
+func parseMajor(version string) (int, error) {
+	return strconv.Atoi(strings.SplitN(version, ".", 2)[0])
+}
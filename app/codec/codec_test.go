@@ -0,0 +1,155 @@
+package codec
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+
+	pb "twist-commander/pb"
+)
+
+var encodings = []string{EncodingBare, EncodingProtobuf, EncodingJSON}
+
+func testCommand() *pb.TransactionCommand {
+	return &pb.TransactionCommand{
+		TransactionID: "tx1",
+		Command:       "confirm",
+		CommandID:     "cmd1",
+		Attempt:       2,
+		InstanceID:    "abc",
+		ReplySubject:  "twist.instance.abc.transaction.tx1.eventEmitted",
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+
+	for _, encoding := range encodings {
+		c, err := CreateCodec(encoding, "test")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := c.Encode(testCommand())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Every encoding is accepted whatever encoding receiver sends
+		for _, receiving := range encodings {
+			r, _ := CreateCodec(receiving, "receiver")
+
+			var cmd pb.TransactionCommand
+			err := r.Decode(data, &cmd)
+			if err != nil {
+				t.Fatalf("%s decoded by %s: %v", encoding, receiving, err)
+			}
+
+			if !proto.Equal(&cmd, testCommand()) {
+				t.Errorf("%s decoded by %s: got %v", encoding, receiving, &cmd)
+			}
+		}
+	}
+}
+
+func TestBareIsReadableByLegacyRunner(t *testing.T) {
+
+	c, _ := CreateCodec("", "test")
+
+	data, err := c.Encode(testCommand())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cmd pb.TransactionCommand
+	if err := proto.Unmarshal(data, &cmd); err != nil {
+		t.Fatal(err)
+	}
+
+	if !proto.Equal(&cmd, testCommand()) {
+		t.Errorf("Got %v", &cmd)
+	}
+}
+
+func TestDecodeBareEvent(t *testing.T) {
+
+	c, _ := CreateCodec(EncodingProtobuf, "test")
+
+	event := &pb.TransactionEvent{
+		TransactionID: "tx1",
+		Type:          pb.TransactionEventType_EVENT_STATE_REPORTED,
+		Sequence:      3,
+		Detail: &pb.TransactionEvent_State{
+			State: &pb.TransactionState{State: "Confirmed", Sequence: 3},
+		},
+	}
+
+	data, _ := proto.Marshal(event)
+
+	var decoded pb.TransactionEvent
+	if err := c.Decode(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if !proto.Equal(&decoded, event) {
+		t.Errorf("Got %v", &decoded)
+	}
+}
+
+func TestDecodeBareWhichLooksLikeJSON(t *testing.T) {
+
+	c, _ := CreateCodec("", "test")
+
+	// Field 1 starts with '\n', and its length of 123 is '{'
+	event := &pb.TransactionEvent{
+		TransactionID: strings.Repeat("a", '{'),
+		Sequence:      1,
+	}
+
+	data, _ := proto.Marshal(event)
+	if data[0] != '\n' || data[1] != '{' {
+		t.Fatalf("Unexpected encoding %q", data[:2])
+	}
+
+	var decoded pb.TransactionEvent
+	if err := c.Decode(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if !proto.Equal(&decoded, event) {
+		t.Errorf("Got %v", &decoded)
+	}
+}
+
+func TestDecodeRejectsEnvelope(t *testing.T) {
+
+	c, _ := CreateCodec(EncodingProtobuf, "test")
+
+	message, _ := ptypes.MarshalAny(testCommand())
+
+	tests := []struct {
+		envelope *pb.Envelope
+		err      error
+	}{
+		{&pb.Envelope{SchemaVersion: "2.0", Message: message}, ErrUnsupportedVersion},
+		{&pb.Envelope{SchemaVersion: "1.3", Message: message}, ErrUnexpectedMessage},
+		{&pb.Envelope{SchemaVersion: "1.0"}, ErrUnexpectedMessage},
+	}
+
+	for _, test := range tests {
+		data, _ := proto.Marshal(test.envelope)
+
+		var event pb.TransactionEvent
+		if err := c.Decode(data, &event); err != test.err {
+			t.Errorf("Envelope %v: expected %v, got %v", test.envelope, test.err, err)
+		}
+	}
+}
+
+func TestCreateCodecRejectsUnknownEncoding(t *testing.T) {
+
+	if _, err := CreateCodec("xml", "test"); err == nil {
+		t.Error("Unknown encoding was accepted")
+	}
+}
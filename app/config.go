@@ -0,0 +1,236 @@
+package app
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+
+	"twist-commander/app/ratelimit"
+	"twist-commander/app/supervisor"
+)
+
+// Config is the subset of settings which can be changed without restarting
+type Config struct {
+	Version           int64                     `json:"version"`
+	LoadedAt          time.Time                 `json:"loadedAt"`
+	LogLevel          string                    `json:"logLevel"`
+	SupervisorHosts   []string                  `json:"supervisorHosts"`
+	SupervisorTimeout time.Duration             `json:"supervisorTimeout"`
+	RateLimitKeyBy    []string                  `json:"rateLimitKeyBy"`
+	RateLimitRules    map[string]ratelimit.Rule `json:"rateLimitRules"`
+	TrustedProxies    []string                  `json:"trustedProxies"`
+	File              string                    `json:"-"`
+
+	// Keys are secrets, so they are never reported
+	adminToken     string
+	apiKeys        map[string]bool
+	trustedProxies []*net.IPNet
+}
+
+func loadConfig() (*Config, error) {
+
+	cfg := &Config{
+		LogLevel:          viper.GetString("log.level"),
+		SupervisorHosts:   viper.GetStringSlice("supervisor.hosts"),
+		SupervisorTimeout: viper.GetDuration("supervisor.timeout"),
+		RateLimitKeyBy:    viper.GetStringSlice("rate_limit.key_by"),
+		RateLimitRules:    make(map[string]ratelimit.Rule),
+		TrustedProxies:    viper.GetStringSlice("rate_limit.trusted_proxies"),
+		File:              viper.ConfigFileUsed(),
+		adminToken:        viper.GetString("admin.token"),
+		apiKeys:           make(map[string]bool),
+	}
+
+	if cfg.LogLevel == "" {
+		cfg.LogLevel = "info"
+	}
+
+	if _, err := log.ParseLevel(cfg.LogLevel); err != nil {
+		return nil, errors.New("Invalid log level: " + cfg.LogLevel)
+	}
+
+	// Supervisors
+	if len(cfg.SupervisorHosts) == 0 {
+		cfg.SupervisorHosts = []string{viper.GetString("supervisor.host")}
+	}
+
+	if viper.GetString("supervisor.protocol") != "nats" {
+		for _, host := range cfg.SupervisorHosts {
+			if _, _, err := net.SplitHostPort(host); err != nil {
+				return nil, errors.New("Invalid supervisor host: " + host)
+			}
+		}
+	}
+
+	if viper.IsSet("supervisor.timeout") && cfg.SupervisorTimeout <= 0 {
+		return nil, errors.New("Invalid supervisor timeout")
+	}
+
+	if cfg.SupervisorTimeout <= 0 {
+		cfg.SupervisorTimeout = supervisor.DefaultTimeout
+	}
+
+	// Rate limits
+	if len(cfg.RateLimitKeyBy) == 0 {
+		cfg.RateLimitKeyBy = []string{"ip"}
+	}
+
+	for _, key := range viper.GetStringSlice("rate_limit.api_keys") {
+		cfg.apiKeys[key] = true
+	}
+
+	for _, key := range cfg.RateLimitKeyBy {
+		// Servers do not terminate TLS, so there are no client certificates
+		if key == "cert" {
+			return nil, errors.New("Rate limiting by client certificate requires TLS, which is not supported")
+		}
+
+		if key != "api_key" && key != "ip" {
+			return nil, errors.New("Invalid rate limit key: " + key)
+		}
+
+		// Key chosen by client would let it pick its own bucket
+		if key == "api_key" && len(cfg.apiKeys) == 0 {
+			return nil, errors.New("Rate limiting by API key requires rate_limit.api_keys")
+		}
+	}
+
+	for _, proxy := range cfg.TrustedProxies {
+		network, err := parseNetwork(proxy)
+		if err != nil {
+			return nil, errors.New("Invalid trusted proxy: " + proxy)
+		}
+
+		cfg.trustedProxies = append(cfg.trustedProxies, network)
+	}
+
+	if viper.GetBool("rate_limit.enabled") {
+		for route := range viper.GetStringMap("rate_limit.routes") {
+
+			rule := ratelimit.Rule{
+				Rate:  viper.GetFloat64("rate_limit.routes." + route + ".rate"),
+				Burst: viper.GetInt("rate_limit.routes." + route + ".burst"),
+			}
+
+			if rule.Rate <= 0 || rule.Burst <= 0 {
+				return nil, errors.New("Invalid rate limit of route: " + route)
+			}
+
+			cfg.RateLimitRules[route] = rule
+		}
+	}
+
+	return cfg, nil
+}
+
+// parseNetwork accepts CIDR or a single address
+func parseNetwork(s string) (*net.IPNet, error) {
+
+	if !strings.Contains(s, "/") {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, errors.New("Invalid address")
+		}
+
+		bits := 8 * net.IPv6len
+		if ip.To4() != nil {
+			ip = ip.To4()
+			bits = 8 * net.IPv4len
+		}
+
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+
+	_, network, err := net.ParseCIDR(s)
+
+	return network, err
+}
+
+// GetConfig returns settings which are active now
+func (a *App) GetConfig() *Config {
+	return a.config.Load().(*Config)
+}
+
+// GetAdminToken returns token of admin APIs, empty if they are disabled
+func (a *App) GetAdminToken() string {
+	return a.GetConfig().adminToken
+}
+
+func (a *App) applyConfig(cfg *Config) error {
+
+	// Only step which can fail goes first, so failed reload changes nothing
+	err := a.supervisor.Reconfigure(cfg.SupervisorHosts, cfg.SupervisorTimeout)
+	if err != nil {
+		return err
+	}
+
+	// Everything else was validated already
+	level, _ := log.ParseLevel(cfg.LogLevel)
+	log.SetLevel(level)
+
+	a.rateLimiter.SetRules(cfg.RateLimitRules)
+
+	cfg.Version = atomic.AddInt64(&a.configVersion, 1)
+	cfg.LoadedAt = time.Now()
+	a.config.Store(cfg)
+
+	log.WithFields(log.Fields{
+		"version": cfg.Version,
+	}).Info("Applied configuration")
+
+	return nil
+}
+
+// ReloadConfig reads configuration file again and applies settings which can be
+// changed at runtime. Current settings are kept if new one is invalid.
+func (a *App) ReloadConfig() error {
+
+	a.configMutex.Lock()
+	defer a.configMutex.Unlock()
+
+	if err := viper.ReadInConfig(); err != nil {
+		log.Error("Failed to read configuration: ", err)
+		return err
+	}
+
+	return a.reloadConfig()
+}
+
+func (a *App) reloadConfig() error {
+
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Error("Rejected configuration: ", err)
+		return err
+	}
+
+	return a.applyConfig(cfg)
+}
+
+// watchConfig reloads configuration once file was changed
+func (a *App) watchConfig() {
+
+	if !viper.GetBool("reload.watch") {
+		return
+	}
+
+	viper.OnConfigChange(func(e fsnotify.Event) {
+
+		log.WithFields(log.Fields{
+			"file": e.Name,
+		}).Info("Configuration file was changed")
+
+		a.configMutex.Lock()
+		defer a.configMutex.Unlock()
+
+		a.reloadConfig()
+	})
+
+	viper.WatchConfig()
+}
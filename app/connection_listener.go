@@ -22,11 +22,20 @@ func (a *App) CreateConnectionListener(host string) error {
 
 	m := cmux.New(lis)
 
+	a.listener = lis
 	a.connectionListener = m
 
 	return nil
 }
 
 func (a *App) Serve() error {
-	return a.connectionListener.Serve()
+
+	err := a.connectionListener.Serve()
+
+	// Listener was closed for shutting down
+	if a.isShuttingDown() {
+		return nil
+	}
+
+	return err
 }
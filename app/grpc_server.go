@@ -1,9 +1,17 @@
 package app
 
 import (
+	"context"
+	"net"
+	"strings"
+
 	log "github.com/sirupsen/logrus"
 	"github.com/soheilhy/cmux"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 
 	app "twist-commander/app/interface"
 	pb "twist-commander/pb"
@@ -12,27 +20,45 @@ import (
 
 type GRPCServer struct {
 	Commander *commander.Service
+	server    *grpc.Server
 }
 
-func (a *App) InitGRPCServer(host string) error {
-	/*
-		// Start to listen on port
-		lis, err := net.Listen("tcp", host)
-		if err != nil {
-			log.Fatal(err)
-			return err
+func (a *App) rateLimitInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+	rpc := info.FullMethod[strings.LastIndex(info.FullMethod, "/")+1:]
+
+	apiKey := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("x-api-key"); len(values) > 0 {
+			apiKey = values[0]
 		}
-	*/
-	lis := a.connectionListener.MatchWithWriters(
-		cmux.HTTP2MatchHeaderFieldSendSettings("content-type", "application/grpc"),
-	)
+	}
 
-	log.WithFields(log.Fields{
-		"host": host,
-	}).Info("Starting gRPC server on " + host)
+	ip := ""
+	if p, ok := peer.FromContext(ctx); ok {
+		ip = p.Addr.String()
+		if host, _, err := net.SplitHostPort(ip); err == nil {
+			ip = host
+		}
+	}
+
+	client := a.identifyClient(apiKey, ip)
+
+	allowed, _ := a.rateLimiter.Allow(rateLimitRoutes[rpc], client)
+	if !allowed {
+		return nil, status.Error(codes.ResourceExhausted, "Rate limit exceeded")
+	}
+
+	return handler(ctx, req)
+}
+
+// InitGRPCServer creates gRPC server and commander service, which HTTP server
+// uses as well
+func (a *App) InitGRPCServer() {
 
 	// Create gRPC server
-	s := grpc.NewServer()
+	s := grpc.NewServer(grpc.UnaryInterceptor(a.rateLimitInterceptor))
+	a.grpcServer.server = s
 
 	// Register data source adapter service
 	commanderService := commander.CreateService(app.AppImpl(a))
@@ -43,9 +69,16 @@ func (a *App) InitGRPCServer(host string) error {
 	log.WithFields(log.Fields{
 		"service": "Commander",
 	}).Info("Registered service")
+}
+
+func (a *App) ServeGRPCServer(host string, lis net.Listener) error {
+
+	log.WithFields(log.Fields{
+		"host": host,
+	}).Info("Starting gRPC server on " + host)
 
 	// Starting server
-	if err := s.Serve(lis); err != cmux.ErrListenerClosed {
+	if err := a.grpcServer.server.Serve(lis); err != nil && err != cmux.ErrListenerClosed && !a.isShuttingDown() {
 		log.Fatal(err)
 		return err
 	}
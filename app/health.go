@@ -0,0 +1,34 @@
+package app
+
+type HealthStatus struct {
+	Healthy    bool              `json:"healthy"`
+	SignalBus  string            `json:"signalbus"`
+	Supervisor bool              `json:"supervisor"`
+	Breakers   map[string]string `json:"breakers"`
+
+	// Cluster peers of embedded signal server
+	SignalServerRoutes *int `json:"signalServerRoutes,omitempty"`
+}
+
+func (a *App) CheckHealth() *HealthStatus {
+
+	health := &HealthStatus{
+		SignalBus:  a.signalbus.State(),
+		Supervisor: a.supervisor != nil && a.supervisor.IsHealthy(),
+		Breakers:   make(map[string]string),
+	}
+
+	health.Healthy = a.signalbus.IsAvailable() && health.Supervisor
+
+	if a.embeddedServer != nil {
+		routes := a.embeddedServer.Routes()
+		health.SignalServerRoutes = &routes
+	}
+
+	for _, b := range a.breakers {
+		state := b.State()
+		health.Breakers[b.Name()] = state.String()
+	}
+
+	return health
+}
@@ -2,13 +2,21 @@ package app
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
 
 	pb "twist-commander/pb"
+	commander "twist-commander/services/commander"
 
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
 	"github.com/soheilhy/cmux"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 type TaskAction struct {
@@ -20,9 +28,14 @@ type TaskAction struct {
 }
 
 type Task struct {
+	ID      string                `json:"id,omitempty"`
 	Actions map[string]TaskAction `json:"actions"`
 }
 
+type CreateTransactionRequest struct {
+	Mode string `json:"mode"`
+}
+
 type ConfirmTransactionRequest struct {
 	Tasks   []Task `json:"tasks"`
 	Expires uint64 `json:"expires"`
@@ -33,13 +46,47 @@ type UpdateTransactionRequest struct {
 	Expires uint64 `json:"expires"`
 }
 
-func prepareTasks(tasks []Task) []*pb.TransactionTask {
+type ReplaceTasksRequest struct {
+	Tasks []Task `json:"tasks"`
+}
+
+type ValidateTasksRequest struct {
+	Tasks []Task `json:"tasks"`
+}
+
+type ForceResolveRequest struct {
+	Outcome       string `json:"outcome"`
+	RedriveCancel bool   `json:"redriveCancel"`
+	Operator      string `json:"operator"`
+	Reason        string `json:"reason"`
+}
+
+type TaskViolation struct {
+	TaskIndex   int32  `json:"taskIndex"`
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+type SimulatedCall struct {
+	Sequence int32             `json:"sequence"`
+	TaskID   string            `json:"taskID"`
+	Action   string            `json:"action"`
+	Method   string            `json:"method"`
+	Uri      string            `json:"uri"`
+	Headers  map[string]string `json:"headers"`
+	Payload  string            `json:"payload"`
+}
+
+func prepareTasks(tasks []Task) ([]*pb.TransactionTask, []*pb.TaskViolation) {
 
 	// Prepare tasks
 	transactionTasks := make([]*pb.TransactionTask, 0)
-	for _, task := range tasks {
+	violations := make([]*pb.TaskViolation, 0)
+	for i, task := range tasks {
 
-		t := &pb.TransactionTask{}
+		t := &pb.TransactionTask{
+			Id: task.ID,
+		}
 		transactionTasks = append(transactionTasks, t)
 
 		for name, action := range task.Actions {
@@ -55,28 +102,208 @@ func prepareTasks(tasks []Task) []*pb.TransactionTask {
 				t.Confirm = act
 			} else if name == "cancel" {
 				t.Cancel = act
+			} else {
+				violations = append(violations, &pb.TaskViolation{
+					TaskIndex:   int32(i),
+					Field:       fmt.Sprintf("tasks[%d].actions.%s", i, name),
+					Description: "unknown action name \"" + name + "\"",
+				})
 			}
 		}
 	}
 
-	return transactionTasks
+	return transactionTasks, violations
 }
 
-func (a *App) InitHTTPServer(host string) error {
+func convertViolations(violations []*pb.TaskViolation) []TaskViolation {
 
-	lis := a.connectionListener.Match(cmux.HTTP1Fast())
+	results := make([]TaskViolation, 0, len(violations))
+	for _, v := range violations {
+		results = append(results, TaskViolation{
+			TaskIndex:   v.TaskIndex,
+			Field:       v.Field,
+			Description: v.Description,
+		})
+	}
+
+	return results
+}
+
+// adminContext passes admin credentials of HTTP request to service
+func adminContext(c *gin.Context) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"authorization", c.GetHeader("Authorization"),
+	))
+}
+
+// HTTP routes and RPCs they serve
+var httpRoutes = map[string]string{
+	"POST /api/transactions":                                "CreateTransaction",
+	"POST /api/transactions/:transactionID":                 "ConfirmTransaction",
+	"PUT /api/transactions/:transactionID":                  "RegisterTasks",
+	"DELETE /api/transactions/:transactionID":               "CancelTransaction",
+	"GET /api/transactions/:transactionID/tasks":            "ListTasks",
+	"PUT /api/transactions/:transactionID/tasks":            "ReplaceTasks",
+	"DELETE /api/transactions/:transactionID/tasks/:taskID": "RemoveTask",
+	"POST /api/tasks:action":                                "ValidateTasks",
+	"POST /api/admin/transactions/:transactionID/resolve":   "ForceResolve",
+	"GET /api/admin/config":                                 "GetConfig",
+	"GET /api/admin/subscriptions":                          "ListSubscriptions",
+}
+
+func (a *App) rateLimitMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		rpc, ok := httpRoutes[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			c.Next()
+			return
+		}
+
+		client := a.identifyClient(c.GetHeader("X-API-Key"), a.clientIP(c.Request.RemoteAddr, c.GetHeader("X-Forwarded-For")))
+
+		allowed, wait := a.rateLimiter.Allow(rateLimitRoutes[rpc], client)
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"success": false,
+				"error":   "Rate limit exceeded",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func (a *App) respondError(c *gin.Context, err error, body gin.H) {
+
+	// Tell client when to come back
+	if status.Code(err) == codes.ResourceExhausted {
+		seconds := int(math.Ceil(a.admission.RetryAfter().Seconds()))
+		c.Header("Retry-After", strconv.Itoa(seconds))
+	}
+
+	c.JSON(httpStatusFromError(err), body)
+}
+
+func httpStatusFromError(err error) int {
+
+	switch status.Code(err) {
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	}
+
+	return http.StatusInternalServerError
+}
+
+func convertTranscript(transcript []*pb.SimulatedCall) []SimulatedCall {
+
+	calls := make([]SimulatedCall, 0, len(transcript))
+	for _, call := range transcript {
+		calls = append(calls, SimulatedCall{
+			Sequence: call.Sequence,
+			TaskID:   call.TaskID,
+			Action:   call.Action,
+			Method:   call.Method,
+			Uri:      call.Uri,
+			Headers:  call.Headers,
+			Payload:  call.Payload,
+		})
+	}
+
+	return calls
+}
+
+func convertTasks(transactionTasks []*pb.TransactionTask) []Task {
+
+	tasks := make([]Task, 0, len(transactionTasks))
+	for _, t := range transactionTasks {
+
+		task := Task{
+			ID:      t.Id,
+			Actions: make(map[string]TaskAction),
+		}
+
+		if t.Confirm != nil {
+			task.Actions["confirm"] = convertTaskAction(t.Confirm)
+		}
+
+		if t.Cancel != nil {
+			task.Actions["cancel"] = convertTaskAction(t.Cancel)
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	return tasks
+}
+
+func convertTaskAction(act *pb.TransactionTaskAction) TaskAction {
+	return TaskAction{
+		Type:    act.Type,
+		Method:  act.Method,
+		Uri:     act.Uri,
+		Headers: act.Headers,
+		Payload: act.Payload,
+	}
+}
+
+// InitHTTPServer creates HTTP server, which serves once ServeHTTPServer is
+// called
+func (a *App) InitHTTPServer() {
 
 	//	gin.SetMode(gin.ReleaseMode)
 
 	r := gin.Default()
 
+	r.Use(a.rateLimitMiddleware())
+
+	// Health
+	r.GET("/health", func(c *gin.Context) {
+
+		health := a.CheckHealth()
+		if !health.Healthy {
+			c.JSON(http.StatusServiceUnavailable, health)
+			return
+		}
+
+		c.JSON(http.StatusOK, health)
+	})
+
+	// Metrics
+	r.GET("/metrics", gin.WrapH(a.createMetricsHandler()))
+
 	// Router
 	r.POST("/api/transactions", func(c *gin.Context) {
 
-		reply, err := a.grpcServer.Commander.CreateTransaction(context.Background(), &pb.CreateTransactionRequest{})
+		// Request body is optional
+		var request CreateTransactionRequest
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&request); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		in := &pb.CreateTransactionRequest{
+			Mode: request.Mode,
+		}
+
+		reply, err := a.grpcServer.Commander.CreateTransaction(context.Background(), in)
 		if err != nil {
 
-			c.JSON(400, gin.H{
+			a.respondError(c, err, gin.H{
 				"success": false,
 			})
 
@@ -99,27 +326,58 @@ func (a *App) InitHTTPServer(host string) error {
 			return
 		}
 
+		tasks, violations := prepareTasks(request.Tasks)
+		if len(violations) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success":       false,
+				"transactionID": c.Param("transactionID"),
+				"violations":    convertViolations(violations),
+			})
+			return
+		}
+
 		in := &pb.ConfirmTransactionRequest{
 			TransactionID: c.Param("transactionID"),
-			Tasks:         prepareTasks(request.Tasks),
+			Tasks:         tasks,
 			//			Expires: request.Expires,
 		}
 
 		reply, err := a.grpcServer.Commander.ConfirmTransaction(context.Background(), in)
 		if err != nil {
 
+			a.respondError(c, err, gin.H{
+				"success":       false,
+				"transactionID": in.TransactionID,
+			})
+
+			return
+		}
+
+		if len(reply.Violations) > 0 {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"success":       false,
 				"transactionID": reply.TransactionID,
+				"violations":    convertViolations(reply.Violations),
 			})
 
 			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{
+		result := gin.H{
 			"success":       reply.Success,
 			"transactionID": reply.TransactionID,
-		})
+			"outcome":       reply.Outcome,
+		}
+
+		if len(reply.OutcomeDetail) > 0 {
+			result["outcomeDetail"] = reply.OutcomeDetail
+		}
+
+		if len(reply.Transcript) > 0 {
+			result["transcript"] = convertTranscript(reply.Transcript)
+		}
+
+		c.JSON(http.StatusOK, result)
 	})
 
 	// Update transaction and register tasks
@@ -131,18 +389,38 @@ func (a *App) InitHTTPServer(host string) error {
 			return
 		}
 
+		tasks, violations := prepareTasks(request.Tasks)
+		if len(violations) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success":       false,
+				"transactionID": c.Param("transactionID"),
+				"violations":    convertViolations(violations),
+			})
+			return
+		}
+
 		in := &pb.RegisterTasksRequest{
 			TransactionID: c.Param("transactionID"),
-			Tasks:         prepareTasks(request.Tasks),
+			Tasks:         tasks,
 			//			Expires: request.Expires,
 		}
 
 		reply, err := a.grpcServer.Commander.RegisterTasks(context.Background(), in)
 		if err != nil {
 
+			a.respondError(c, err, gin.H{
+				"success":       false,
+				"transactionID": in.TransactionID,
+			})
+
+			return
+		}
+
+		if len(reply.Violations) > 0 {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"success":       false,
 				"transactionID": reply.TransactionID,
+				"violations":    convertViolations(reply.Violations),
 			})
 
 			return
@@ -164,9 +442,121 @@ func (a *App) InitHTTPServer(host string) error {
 		reply, err := a.grpcServer.Commander.CancelTransaction(context.Background(), in)
 		if err != nil {
 
+			a.respondError(c, err, gin.H{
+				"success":       false,
+				"transactionID": in.TransactionID,
+			})
+
+			return
+		}
+
+		result := gin.H{
+			"success":       reply.Success,
+			"transactionID": reply.TransactionID,
+			"outcome":       reply.Outcome,
+		}
+
+		if len(reply.OutcomeDetail) > 0 {
+			result["outcomeDetail"] = reply.OutcomeDetail
+		}
+
+		if len(reply.Transcript) > 0 {
+			result["transcript"] = convertTranscript(reply.Transcript)
+		}
+
+		c.JSON(http.StatusOK, result)
+	})
+
+	// List tasks
+	r.GET("/api/transactions/:transactionID/tasks", func(c *gin.Context) {
+
+		in := &pb.ListTasksRequest{
+			TransactionID: c.Param("transactionID"),
+		}
+
+		reply, err := a.grpcServer.Commander.ListTasks(context.Background(), in)
+		if err != nil {
+
+			a.respondError(c, err, gin.H{
+				"success":       false,
+				"transactionID": in.TransactionID,
+			})
+
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success":       reply.Success,
+			"transactionID": reply.TransactionID,
+			"tasks":         convertTasks(reply.Tasks),
+		})
+	})
+
+	// Replace all registered tasks
+	r.PUT("/api/transactions/:transactionID/tasks", func(c *gin.Context) {
+
+		var request ReplaceTasksRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		tasks, violations := prepareTasks(request.Tasks)
+		if len(violations) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success":       false,
+				"transactionID": c.Param("transactionID"),
+				"violations":    convertViolations(violations),
+			})
+			return
+		}
+
+		in := &pb.ReplaceTasksRequest{
+			TransactionID: c.Param("transactionID"),
+			Tasks:         tasks,
+		}
+
+		reply, err := a.grpcServer.Commander.ReplaceTasks(context.Background(), in)
+		if err != nil {
+
+			a.respondError(c, err, gin.H{
+				"success":       false,
+				"transactionID": in.TransactionID,
+			})
+
+			return
+		}
+
+		if len(reply.Violations) > 0 {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"success":       false,
 				"transactionID": reply.TransactionID,
+				"violations":    convertViolations(reply.Violations),
+			})
+
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success":       reply.Success,
+			"transactionID": reply.TransactionID,
+		})
+	})
+
+	// Remove specific task
+	r.DELETE("/api/transactions/:transactionID/tasks/:taskID", func(c *gin.Context) {
+
+		in := &pb.RemoveTaskRequest{
+			TransactionID: c.Param("transactionID"),
+			TaskID:        c.Param("taskID"),
+		}
+
+		reply, err := a.grpcServer.Commander.RemoveTask(context.Background(), in)
+		if err != nil {
+
+			a.respondError(c, err, gin.H{
+				"success":       false,
+				"transactionID": in.TransactionID,
 			})
 
 			return
@@ -178,16 +568,142 @@ func (a *App) InitHTTPServer(host string) error {
 		})
 	})
 
-	s := &http.Server{
+	// Force resolve stuck transaction
+	r.POST("/api/admin/transactions/:transactionID/resolve", func(c *gin.Context) {
+
+		var request ForceResolveRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		in := &pb.ForceResolveRequest{
+			TransactionID: c.Param("transactionID"),
+			Outcome:       request.Outcome,
+			RedriveCancel: request.RedriveCancel,
+			Operator:      request.Operator,
+			Reason:        request.Reason,
+		}
+
+		reply, err := a.grpcServer.Commander.ForceResolve(adminContext(c), in)
+		if err != nil {
+			a.respondError(c, err, gin.H{
+				"success":       false,
+				"transactionID": in.TransactionID,
+				"error":         status.Convert(err).Message(),
+			})
+
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success":       reply.Success,
+			"transactionID": reply.TransactionID,
+		})
+	})
+
+	// Active configuration
+	r.GET("/api/admin/config", func(c *gin.Context) {
+
+		err := commander.AuthorizeAdmin(adminContext(c), a.GetAdminToken())
+		if err != nil {
+			a.respondError(c, err, gin.H{
+				"success": false,
+				"error":   status.Convert(err).Message(),
+			})
+
+			return
+		}
+
+		cfg := a.GetConfig()
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"file":    cfg.File,
+			"config":  cfg,
+		})
+	})
+
+	// Active subscriptions on signal server
+	r.GET("/api/admin/subscriptions", func(c *gin.Context) {
+
+		err := commander.AuthorizeAdmin(adminContext(c), a.GetAdminToken())
+		if err != nil {
+			a.respondError(c, err, gin.H{
+				"success": false,
+				"error":   status.Convert(err).Message(),
+			})
+
+			return
+		}
+
+		subscriptions := a.signalbus.Subscriptions()
+
+		leaked := 0
+		for _, s := range subscriptions {
+			if s.Leaked {
+				leaked++
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success":       true,
+			"count":         len(subscriptions),
+			"leaked":        leaked,
+			"subscriptions": subscriptions,
+		})
+	})
+
+	// Validate task definitions without registering them. Router cannot match a
+	// literal colon, so custom method is captured as parameter.
+	r.POST("/api/tasks:action", func(c *gin.Context) {
+
+		if c.Param("action") != ":validate" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Unknown action"})
+			return
+		}
+
+		var request ValidateTasksRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		tasks, violations := prepareTasks(request.Tasks)
+
+		in := &pb.ValidateTasksRequest{
+			Tasks: tasks,
+		}
+
+		reply, err := a.grpcServer.Commander.ValidateTasks(context.Background(), in)
+		if err != nil {
+			a.respondError(c, err, gin.H{"error": err.Error()})
+			return
+		}
+
+		violations = append(violations, reply.Violations...)
+
+		// Action names only exist in JSON form of tasks, anything else is
+		// validated by service
+		c.JSON(http.StatusOK, gin.H{
+			"success":    reply.Success && len(violations) == 0,
+			"violations": convertViolations(violations),
+		})
+	})
+
+	a.httpServer = &http.Server{
 		Handler: r,
 	}
+}
+
+func (a *App) ServeHTTPServer(host string, lis net.Listener) error {
 
 	log.WithFields(log.Fields{
 		"host": host,
 	}).Info("Starting HTTP server on " + host)
 
 	// Starting server
-	if err := s.Serve(lis); err != cmux.ErrListenerClosed {
+	if err := a.httpServer.Serve(lis); err != cmux.ErrListenerClosed && err != http.ErrServerClosed && !a.isShuttingDown() {
 		log.Fatal(err)
 		return err
 	}
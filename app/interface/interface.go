@@ -1,12 +1,102 @@
 package app
 
-import "github.com/nats-io/nats.go"
+import (
+	"time"
+
+	pb "twist-commander/pb"
+
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/net/context"
+)
+
+// Message is a signal received from signal bus, independent of transport
+type Message struct {
+	Subject string
+	Reply   string
+	Data    []byte
+}
+
+type Subscription interface {
+	Subject() string
+	IsValid() bool
+	Unsubscribe() error
+}
 
 type SignalBusImpl interface {
 	Emit(string, []byte) error
-	Watch(string, func(*nats.Msg)) (*nats.Subscription, error)
+	Watch(string, string, func(*Message)) (Subscription, error)
+	Unwatch(Subscription) error
+	State() string
+	IsAvailable() bool
+	NotifyUnavailable(func())
+	Request(context.Context, string, []byte) ([]byte, error)
+}
+
+// SupervisorClient is a connection to supervisor over specific transport
+type SupervisorClient interface {
+	Connect() error
+	Close()
+	IsHealthy() bool
+	PrepareTransaction(context.Context, *pb.PrepareTransactionRequest) (*pb.PrepareTransactionReply, error)
+	Reconfigure(hosts []string, timeout time.Duration) error
+}
+
+type AdmissionImpl interface {
+	Acquire(context.Context, string) (func(), error)
+	Reserve(string) (func(), error)
+}
+
+type SubjectImpl interface {
+	TransactionEvents(string) (string, error)
+	TransactionCommands(string) (string, error)
+	TransactionRequests(string) (string, error)
+	InstanceEvents(string, string) (string, error)
+}
+
+// CodecImpl wraps messages on signal bus into versioned envelope
+type CodecImpl interface {
+	Encode(proto.Message) ([]byte, error)
+	Decode([]byte, proto.Message) error
+}
+
+// Transaction is what commander knows about a transaction beyond one call
+type Transaction struct {
+	TransactionID string                `json:"transactionID"`
+	Mode          string                `json:"mode"`
+	InstanceID    string                `json:"instanceID,omitempty"`
+	State         string                `json:"state"`
+	Tasks         []*pb.TransactionTask `json:"tasks,omitempty"`
+	Outcome       string                `json:"outcome,omitempty"`
+	OutcomeDetail string                `json:"outcomeDetail,omitempty"`
+	Transitions   []Transition          `json:"transitions"`
+	CreatedAt     time.Time             `json:"createdAt"`
+	UpdatedAt     time.Time             `json:"updatedAt"`
+}
+
+type Transition struct {
+	State  string    `json:"state"`
+	Reason string    `json:"reason,omitempty"`
+	At     time.Time `json:"at"`
+}
+
+// TransactionStore records creation, tasks, state transitions and outcome of
+// transactions. Get and List return copies, Update modifies transaction
+// atomically.
+type TransactionStore interface {
+	Create(*Transaction) error
+	Get(string) (*Transaction, error)
+	List() ([]*Transaction, error)
+	Update(string, func(*Transaction)) error
+	Close() error
 }
 
 type AppImpl interface {
 	GetSignalBus() SignalBusImpl
+	GetSupervisorClient() SupervisorClient
+	GetAdmission() AdmissionImpl
+	GetSubjects() SubjectImpl
+	GetCodec() CodecImpl
+	GetInstanceID() string
+	GetAdminToken() string
+	GetStore() TransactionStore
 }
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+func (a *App) createMetricsHandler() http.Handler {
+
+	registry := prometheus.NewRegistry()
+
+	// Circuit breakers
+	for _, b := range a.breakers {
+
+		br := b
+
+		registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name:        "twist_commander_circuit_breaker_state",
+			Help:        "State of circuit breaker (0 = closed, 1 = half-open, 2 = open)",
+			ConstLabels: prometheus.Labels{"name": br.Name()},
+		}, func() float64 {
+			return float64(br.State())
+		}))
+
+		registry.MustRegister(prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name:        "twist_commander_circuit_breaker_rejected_total",
+			Help:        "Number of calls rejected by open circuit breaker",
+			ConstLabels: prometheus.Labels{"name": br.Name()},
+		}, func() float64 {
+			return float64(br.Rejected())
+		}))
+	}
+
+	// Admission control
+	for _, l := range a.admission.Limiters() {
+
+		limiter := l
+
+		registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name:        "twist_commander_admission_in_flight",
+			Help:        "Number of requests being processed",
+			ConstLabels: prometheus.Labels{"operation": limiter.Name()},
+		}, func() float64 {
+			return float64(limiter.InFlight())
+		}))
+
+		registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name:        "twist_commander_admission_queued",
+			Help:        "Number of requests waiting for a free slot",
+			ConstLabels: prometheus.Labels{"operation": limiter.Name()},
+		}, func() float64 {
+			return float64(limiter.Queued())
+		}))
+	}
+
+	// Signal bus subscriptions
+	registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "twist_commander_signalbus_subscriptions",
+		Help: "Number of active subscriptions on signal server",
+	}, func() float64 {
+		return float64(a.signalbus.SubscriptionCount())
+	}))
+
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"net"
+	"strings"
+)
+
+// Routes which are limited separately, shared by HTTP and gRPC
+var rateLimitRoutes = map[string]string{
+	"CreateTransaction":  "create",
+	"ConfirmTransaction": "confirm",
+	"CancelTransaction":  "cancel",
+	"RegisterTasks":      "tasks",
+	"ListTasks":          "tasks",
+	"ReplaceTasks":       "tasks",
+	"RemoveTask":         "tasks",
+	"ValidateTasks":      "validate",
+	"ForceResolve":       "admin",
+	"GetConfig":          "admin",
+	"ListSubscriptions":  "admin",
+}
+
+// identifyClient picks the first available identity in configured order. API
+// key is only used when it is one of configured keys.
+func (a *App) identifyClient(apiKey string, ip string) string {
+
+	cfg := a.GetConfig()
+
+	for _, key := range cfg.RateLimitKeyBy {
+		switch strings.ToLower(key) {
+		case "api_key":
+			if apiKey != "" && cfg.apiKeys[apiKey] {
+				return "key:" + apiKey
+			}
+		case "ip":
+			if ip != "" {
+				return "ip:" + ip
+			}
+		}
+	}
+
+	return "ip:" + ip
+}
+
+// clientIP returns address of HTTP client. X-Forwarded-For is only believed
+// when request came from a trusted proxy, and the nearest address which is not
+// a trusted proxy is taken from it.
+func (a *App) clientIP(remoteAddr string, forwardedFor string) string {
+
+	ip := remoteAddr
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		ip = host
+	}
+
+	proxies := a.GetConfig().trustedProxies
+	if !isTrusted(proxies, ip) || forwardedFor == "" {
+		return ip
+	}
+
+	hops := strings.Split(forwardedFor, ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+
+		ip = hop
+		if !isTrusted(proxies, hop) {
+			break
+		}
+	}
+
+	return ip
+}
+
+func isTrusted(proxies []*net.IPNet, ip string) bool {
+
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+
+	for _, network := range proxies {
+		if network.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,132 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Rule is a token bucket setting, rate is number of requests per second
+type Rule struct {
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+}
+
+type bucket struct {
+	route    string
+	rule     Rule
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// RateLimiter keeps a token bucket for every client of every route
+type RateLimiter struct {
+	rules       map[string]Rule
+	idleTimeout time.Duration
+
+	mutex     sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func CreateRateLimiter(idleTimeout time.Duration) *RateLimiter {
+
+	if idleTimeout <= 0 {
+		idleTimeout = 10 * time.Minute
+	}
+
+	return &RateLimiter{
+		rules:       make(map[string]Rule),
+		idleTimeout: idleTimeout,
+		buckets:     make(map[string]*bucket),
+		lastSweep:   time.Now(),
+	}
+}
+
+// SetRules replaces limits of all routes. Rule of "default" route applies to
+// routes which have no rule.
+func (rl *RateLimiter) SetRules(rules map[string]Rule) {
+
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	rl.rules = rules
+
+	// Clients keep tokens of routes whose limit was not changed
+	for key, b := range rl.buckets {
+		rule, ok := rl.ruleOf(b.route)
+		if !ok || rule != b.rule {
+			delete(rl.buckets, key)
+		}
+	}
+}
+
+func (rl *RateLimiter) ruleOf(route string) (Rule, bool) {
+
+	rule, ok := rl.rules[route]
+	if !ok {
+		rule, ok = rl.rules["default"]
+	}
+
+	return rule, ok
+}
+
+// Allow consumes a token of client for route. It returns false and time to
+// wait if client has run out of tokens.
+func (rl *RateLimiter) Allow(route string, client string) (bool, time.Duration) {
+
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	rule, ok := rl.ruleOf(route)
+	if !ok {
+		return true, 0
+	}
+
+	now := time.Now()
+
+	rl.sweep(now)
+
+	key := route + "/" + client
+	b, ok := rl.buckets[key]
+	if !ok {
+		b = &bucket{
+			route:   route,
+			rule:    rule,
+			limiter: rate.NewLimiter(rate.Limit(rule.Rate), rule.Burst),
+		}
+		rl.buckets[key] = b
+	}
+
+	b.lastSeen = now
+
+	r := b.limiter.ReserveN(now, 1)
+	if !r.OK() {
+		return false, time.Second
+	}
+
+	delay := r.DelayFrom(now)
+	if delay > 0 {
+		r.CancelAt(now)
+		return false, delay
+	}
+
+	return true, 0
+}
+
+// sweep drops buckets of clients which are gone
+func (rl *RateLimiter) sweep(now time.Time) {
+
+	if now.Sub(rl.lastSweep) < time.Minute {
+		return
+	}
+
+	rl.lastSweep = now
+
+	for key, b := range rl.buckets {
+		if now.Sub(b.lastSeen) > rl.idleTimeout {
+			delete(rl.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package ratelimit
+
+import (
+	"testing"
+)
+
+func TestSetRulesKeepsUnchangedBuckets(t *testing.T) {
+
+	rl := CreateRateLimiter(0)
+	rl.SetRules(map[string]Rule{
+		"create":  {Rate: 1, Burst: 1},
+		"confirm": {Rate: 1, Burst: 1},
+	})
+
+	for _, route := range []string{"create", "confirm"} {
+		if allowed, _ := rl.Allow(route, "client"); !allowed {
+			t.Fatal("First request of " + route + " was rejected")
+		}
+	}
+
+	// Only limit of confirm is changed
+	rl.SetRules(map[string]Rule{
+		"create":  {Rate: 1, Burst: 1},
+		"confirm": {Rate: 1, Burst: 2},
+	})
+
+	if allowed, _ := rl.Allow("create", "client"); allowed {
+		t.Error("Bucket of unchanged route was reset")
+	}
+
+	if allowed, _ := rl.Allow("confirm", "client"); !allowed {
+		t.Error("Bucket of changed route was kept")
+	}
+}
+
+func TestSetRulesDropsBucketsOfRemovedRoutes(t *testing.T) {
+
+	rl := CreateRateLimiter(0)
+	rl.SetRules(map[string]Rule{"default": {Rate: 1, Burst: 1}})
+
+	rl.Allow("create", "client")
+
+	rl.SetRules(map[string]Rule{})
+
+	if allowed, _ := rl.Allow("create", "client"); !allowed {
+		t.Error("Route without limit was limited")
+	}
+
+	if len(rl.buckets) != 0 {
+		t.Errorf("Expected no buckets, got %d", len(rl.buckets))
+	}
+}
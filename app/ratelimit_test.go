@@ -0,0 +1,98 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func createTestApp(t *testing.T, settings map[string]interface{}) *App {
+
+	viper.Reset()
+	for key, value := range settings {
+		viper.Set(key, value)
+	}
+	defer viper.Reset()
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := &App{}
+	a.config.Store(cfg)
+
+	return a
+}
+
+func TestIdentifyClientIgnoresUnknownAPIKeys(t *testing.T) {
+
+	a := createTestApp(t, map[string]interface{}{
+		"supervisor.host":     "127.0.0.1:45556",
+		"rate_limit.key_by":   []string{"api_key", "ip"},
+		"rate_limit.api_keys": []string{"known"},
+	})
+
+	if client := a.identifyClient("known", "10.0.0.1"); client != "key:known" {
+		t.Errorf("expected known key, got %s", client)
+	}
+
+	if client := a.identifyClient("rotated", "10.0.0.1"); client != "ip:10.0.0.1" {
+		t.Errorf("expected unknown key to fall back to IP, got %s", client)
+	}
+}
+
+func TestLoadConfigRejectsAPIKeyWithoutKeys(t *testing.T) {
+
+	viper.Reset()
+	defer viper.Reset()
+
+	viper.Set("supervisor.host", "127.0.0.1:45556")
+	viper.Set("rate_limit.key_by", []string{"api_key"})
+
+	if _, err := loadConfig(); err == nil {
+		t.Fatal("expected configuration to be rejected")
+	}
+}
+
+func TestLoadConfigRejectsCertWithoutTLS(t *testing.T) {
+
+	viper.Reset()
+	defer viper.Reset()
+
+	viper.Set("supervisor.host", "127.0.0.1:45556")
+	viper.Set("rate_limit.key_by", []string{"cert", "ip"})
+
+	if _, err := loadConfig(); err == nil {
+		t.Fatal("expected configuration to be rejected")
+	}
+}
+
+func TestClientIP(t *testing.T) {
+
+	a := createTestApp(t, map[string]interface{}{
+		"supervisor.host":            "127.0.0.1:45556",
+		"rate_limit.trusted_proxies": []string{"10.0.0.0/8", "192.168.1.1"},
+	})
+
+	cases := []struct {
+		remote    string
+		forwarded string
+		ip        string
+	}{
+		// Client talking directly cannot forge its address
+		{"203.0.113.5:1234", "1.2.3.4", "203.0.113.5"},
+		{"10.1.1.1:1234", "", "10.1.1.1"},
+		{"10.1.1.1:1234", "198.51.100.7", "198.51.100.7"},
+
+		// Address prepended by client is ignored behind a chain of proxies
+		{"10.1.1.1:1234", "1.2.3.4, 198.51.100.7, 192.168.1.1", "198.51.100.7"},
+		{"10.1.1.1:1234", "garbage", "10.1.1.1"},
+	}
+
+	for _, c := range cases {
+		if ip := a.clientIP(c.remote, c.forwarded); ip != c.ip {
+			t.Errorf("clientIP(%q, %q) = %s, expected %s", c.remote, c.forwarded, ip, c.ip)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package signalbus
+
+import (
+	app "twist-commander/app/interface"
+)
+
+// Transports of signal bus
+const (
+	TransportNATS      = "nats"
+	TransportStreaming = "streaming"
+	TransportMemory    = "memory"
+)
+
+// Bus is signal bus as seen by App, which also owns its lifecycle
+type Bus interface {
+	app.SignalBusImpl
+	Connect() error
+	Close()
+	Subscriptions() []SubscriptionInfo
+	SubscriptionCount() int
+}
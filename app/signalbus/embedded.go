@@ -0,0 +1,166 @@
+package signalbus
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	log "github.com/sirupsen/logrus"
+)
+
+type EmbeddedOptions struct {
+	Host string
+	Port int
+
+	// Cluster is disabled unless port is set
+	ClusterHost string
+	ClusterPort int
+
+	// Peers in form of "nats-route://host:port"
+	Routes []string
+
+	StartTimeout time.Duration
+}
+
+// EmbeddedServer runs NATS server inside commander, so small deployments do not
+// need a separate installation. Runners connect to it like to any NATS server.
+type EmbeddedServer struct {
+	options EmbeddedOptions
+	server  *server.Server
+}
+
+func CreateEmbeddedServer(opts EmbeddedOptions) *EmbeddedServer {
+
+	if opts.Host == "" {
+		opts.Host = "0.0.0.0"
+	}
+
+	if opts.Port == 0 {
+		opts.Port = 4222
+	}
+
+	if opts.ClusterHost == "" {
+		opts.ClusterHost = opts.Host
+	}
+
+	if opts.StartTimeout <= 0 {
+		opts.StartTimeout = 10 * time.Second
+	}
+
+	return &EmbeddedServer{
+		options: opts,
+	}
+}
+
+func (es *EmbeddedServer) Start() error {
+
+	opts := &server.Options{
+		Host:   es.options.Host,
+		Port:   es.options.Port,
+		NoSigs: true,
+	}
+
+	if es.options.ClusterPort > 0 {
+		opts.Cluster = server.ClusterOpts{
+			Host: es.options.ClusterHost,
+			Port: es.options.ClusterPort,
+		}
+
+		for _, route := range es.options.Routes {
+			u, err := url.Parse(route)
+			if err != nil || u.Host == "" {
+				return errors.New("Invalid route of embedded signal server: " + route)
+			}
+
+			opts.Routes = append(opts.Routes, u)
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"host":        es.options.Host,
+		"port":        es.options.Port,
+		"clusterPort": es.options.ClusterPort,
+		"routes":      es.options.Routes,
+	}).Info("Starting embedded signal server")
+
+	s, err := server.NewServer(opts)
+	if err != nil {
+		return err
+	}
+
+	s.SetLogger(&embeddedLogger{}, false, false)
+
+	go s.Start()
+
+	if !s.ReadyForConnections(es.options.StartTimeout) {
+		s.Shutdown()
+		return errors.New("Embedded signal server did not start in time")
+	}
+
+	es.server = s
+
+	return nil
+}
+
+// ClientURL returns address for connecting to embedded server from the same host
+func (es *EmbeddedServer) ClientURL() string {
+
+	host := es.options.Host
+	if ip := net.ParseIP(host); ip != nil && ip.IsUnspecified() {
+		host = "127.0.0.1"
+	}
+
+	return "nats://" + net.JoinHostPort(host, strconv.Itoa(es.options.Port))
+}
+
+// Routes returns number of connected cluster peers
+func (es *EmbeddedServer) Routes() int {
+
+	if es.server == nil {
+		return 0
+	}
+
+	return es.server.NumRoutes()
+}
+
+func (es *EmbeddedServer) Shutdown() {
+
+	if es.server == nil {
+		return
+	}
+
+	log.Info("Stopping embedded signal server")
+
+	es.server.Shutdown()
+}
+
+// embeddedLogger forwards logs of embedded server to application logger
+type embeddedLogger struct{}
+
+func (l *embeddedLogger) Noticef(format string, v ...interface{}) {
+	log.WithField("component", "nats").Info(fmt.Sprintf(format, v...))
+}
+
+func (l *embeddedLogger) Warnf(format string, v ...interface{}) {
+	log.WithField("component", "nats").Warn(fmt.Sprintf(format, v...))
+}
+
+func (l *embeddedLogger) Fatalf(format string, v ...interface{}) {
+	log.WithField("component", "nats").Error(fmt.Sprintf(format, v...))
+}
+
+func (l *embeddedLogger) Errorf(format string, v ...interface{}) {
+	log.WithField("component", "nats").Error(fmt.Sprintf(format, v...))
+}
+
+func (l *embeddedLogger) Debugf(format string, v ...interface{}) {
+	log.WithField("component", "nats").Debug(fmt.Sprintf(format, v...))
+}
+
+func (l *embeddedLogger) Tracef(format string, v ...interface{}) {
+	log.WithField("component", "nats").Trace(fmt.Sprintf(format, v...))
+}
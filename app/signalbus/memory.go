@@ -0,0 +1,426 @@
+package signalbus
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	app "twist-commander/app/interface"
+)
+
+var (
+	ErrClosed         = status.Error(codes.Unavailable, "Signal bus is closed")
+	ErrInvalidSubject = errors.New("Invalid subject")
+)
+
+// MemoryBus delivers signals inside the process with the same subject rules as
+// NATS: tokens are separated by dots, "*" matches a single token and ">" matches
+// all remaining tokens. It is meant for running commander and runner in one
+// process.
+type MemoryBus struct {
+	mutex          sync.RWMutex
+	connected      bool
+	closed         bool
+	dispatcher     *dispatcher
+	unavailableFns []func()
+	registry       *Registry
+	stop           chan struct{}
+	inboxes        uint64
+}
+
+func CreateMemoryBus(opts Options) *MemoryBus {
+	return &MemoryBus{
+		dispatcher: createDispatcher(),
+		registry:   CreateRegistry(opts.LeakThreshold),
+		stop:       make(chan struct{}),
+	}
+}
+
+func (mb *MemoryBus) Connect() error {
+
+	log.Info("Using in-memory signal bus")
+
+	mb.mutex.Lock()
+	defer mb.mutex.Unlock()
+
+	if mb.closed {
+		return ErrClosed
+	}
+
+	mb.connected = true
+
+	go mb.registry.DetectLeaks(mb.stop)
+
+	return nil
+}
+
+func (mb *MemoryBus) Close() {
+
+	mb.mutex.Lock()
+	if mb.closed {
+		mb.mutex.Unlock()
+		return
+	}
+
+	mb.closed = true
+	close(mb.stop)
+	fns := append([]func(){}, mb.unavailableFns...)
+	mb.mutex.Unlock()
+
+	mb.registry.UnsubscribeAll()
+
+	// Subscriptions which were not registered
+	mb.dispatcher.stopAll()
+
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+func (mb *MemoryBus) State() string {
+
+	mb.mutex.RLock()
+	defer mb.mutex.RUnlock()
+
+	if mb.closed {
+		return StateClosed
+	}
+
+	if mb.connected {
+		return StateConnected
+	}
+
+	return StateDisconnected
+}
+
+func (mb *MemoryBus) IsAvailable() bool {
+	return mb.State() == StateConnected
+}
+
+// NotifyUnavailable registers a function which is called when bus is closed
+func (mb *MemoryBus) NotifyUnavailable(fn func()) {
+
+	mb.mutex.Lock()
+	defer mb.mutex.Unlock()
+
+	mb.unavailableFns = append(mb.unavailableFns, fn)
+}
+
+func (mb *MemoryBus) Emit(topic string, data []byte) error {
+	return mb.publish(topic, "", data)
+}
+
+func (mb *MemoryBus) publish(topic string, reply string, data []byte) error {
+
+	tokens, ok := parseSubject(topic, false)
+	if !ok {
+		return ErrInvalidSubject
+	}
+
+	mb.mutex.RLock()
+	defer mb.mutex.RUnlock()
+
+	if !mb.connected || mb.closed {
+		return ErrClosed
+	}
+
+	mb.dispatcher.dispatch(&app.Message{
+		Subject: topic,
+		Reply:   reply,
+		Data:    data,
+	}, tokens)
+
+	return nil
+}
+
+func (mb *MemoryBus) Watch(topic string, creator string, fn func(*app.Message)) (app.Subscription, error) {
+
+	sub, err := mb.subscribe(topic, fn)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add to subscription list
+	mb.registry.Add(sub, creator)
+
+	return sub, nil
+}
+
+func (mb *MemoryBus) subscribe(topic string, fn func(*app.Message)) (*memorySubscription, error) {
+
+	mb.mutex.Lock()
+	defer mb.mutex.Unlock()
+
+	if !mb.connected || mb.closed {
+		return nil, ErrClosed
+	}
+
+	return mb.dispatcher.subscribe(topic, fn)
+}
+
+func (mb *MemoryBus) Unwatch(sub app.Subscription) error {
+
+	mb.registry.Remove(sub)
+
+	return sub.Unsubscribe()
+}
+
+func (mb *MemoryBus) Request(ctx context.Context, topic string, data []byte) ([]byte, error) {
+
+	inbox := "_INBOX." + strconv.FormatUint(atomic.AddUint64(&mb.inboxes, 1), 10)
+	replies := make(chan []byte, 1)
+
+	sub, err := mb.subscribe(inbox, func(msg *app.Message) {
+		select {
+		case replies <- msg.Data:
+		default:
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer sub.Unsubscribe()
+
+	err = mb.publish(topic, inbox, data)
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case data := <-replies:
+		return data, nil
+	}
+}
+
+func (mb *MemoryBus) SubscriptionCount() int {
+	return mb.registry.Count()
+}
+
+// Subscriptions returns active subscriptions
+func (mb *MemoryBus) Subscriptions() []SubscriptionInfo {
+	return mb.registry.List()
+}
+
+// dispatcher delivers messages to subscriptions whose subject matches, in the
+// process. It is shared by transports which receive everything on one
+// connection.
+type dispatcher struct {
+	mutex         sync.RWMutex
+	subscriptions map[*memorySubscription]bool
+}
+
+func createDispatcher() *dispatcher {
+	return &dispatcher{
+		subscriptions: make(map[*memorySubscription]bool),
+	}
+}
+
+func (d *dispatcher) subscribe(topic string, fn func(*app.Message)) (*memorySubscription, error) {
+
+	tokens, ok := parseSubject(topic, true)
+	if !ok {
+		return nil, ErrInvalidSubject
+	}
+
+	sub := &memorySubscription{
+		dispatcher: d,
+		subject:    topic,
+		tokens:     tokens,
+		fn:         fn,
+	}
+	sub.cond = sync.NewCond(&sub.mutex)
+
+	d.mutex.Lock()
+	d.subscriptions[sub] = true
+	d.mutex.Unlock()
+
+	go sub.deliver()
+
+	return sub, nil
+}
+
+// dispatch queues message for every matching subscription, and reports
+// whether there was any
+func (d *dispatcher) dispatch(msg *app.Message, tokens []string) bool {
+
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	matched := false
+
+	for sub := range d.subscriptions {
+		if matchSubject(sub.tokens, tokens) {
+			matched = true
+
+			// Every subscriber gets its own copy, like it came from network
+			sub.push(&app.Message{
+				Subject: msg.Subject,
+				Reply:   msg.Reply,
+				Data:    append([]byte(nil), msg.Data...),
+			})
+		}
+	}
+
+	return matched
+}
+
+func (d *dispatcher) remove(sub *memorySubscription) bool {
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if !d.subscriptions[sub] {
+		return false
+	}
+
+	delete(d.subscriptions, sub)
+
+	return true
+}
+
+func (d *dispatcher) stopAll() {
+
+	d.mutex.Lock()
+	subs := d.subscriptions
+	d.subscriptions = make(map[*memorySubscription]bool)
+	d.mutex.Unlock()
+
+	for sub := range subs {
+		sub.stop()
+	}
+}
+
+// memorySubscription queues messages, so publisher is never blocked by a slow
+// subscriber and messages are handled in order
+type memorySubscription struct {
+	dispatcher *dispatcher
+	subject    string
+	tokens     []string
+	fn         func(*app.Message)
+
+	mutex   sync.Mutex
+	cond    *sync.Cond
+	queue   []*app.Message
+	stopped bool
+}
+
+func (sub *memorySubscription) Subject() string {
+	return sub.subject
+}
+
+func (sub *memorySubscription) IsValid() bool {
+
+	sub.mutex.Lock()
+	defer sub.mutex.Unlock()
+
+	return !sub.stopped
+}
+
+func (sub *memorySubscription) Unsubscribe() error {
+
+	if !sub.dispatcher.remove(sub) {
+		return ErrInvalidSubject
+	}
+
+	sub.stop()
+
+	return nil
+}
+
+func (sub *memorySubscription) push(msg *app.Message) {
+
+	sub.mutex.Lock()
+	defer sub.mutex.Unlock()
+
+	if sub.stopped {
+		return
+	}
+
+	sub.queue = append(sub.queue, msg)
+	sub.cond.Signal()
+}
+
+func (sub *memorySubscription) stop() {
+
+	sub.mutex.Lock()
+	defer sub.mutex.Unlock()
+
+	sub.stopped = true
+	sub.queue = nil
+	sub.cond.Signal()
+}
+
+func (sub *memorySubscription) deliver() {
+
+	for {
+		sub.mutex.Lock()
+		for len(sub.queue) == 0 && !sub.stopped {
+			sub.cond.Wait()
+		}
+
+		if sub.stopped {
+			sub.mutex.Unlock()
+			return
+		}
+
+		msg := sub.queue[0]
+		sub.queue = sub.queue[1:]
+		sub.mutex.Unlock()
+
+		sub.fn(msg)
+	}
+}
+
+// parseSubject splits subject into tokens, wildcards are allowed for
+// subscriptions only and ">" must be the last token
+func parseSubject(subject string, wildcards bool) ([]string, bool) {
+
+	if subject == "" || strings.ContainsAny(subject, " \t\r\n") {
+		return nil, false
+	}
+
+	tokens := strings.Split(subject, ".")
+	for i, token := range tokens {
+
+		if token == "" {
+			return nil, false
+		}
+
+		if token == "*" || token == ">" {
+			if !wildcards || (token == ">" && i != len(tokens)-1) {
+				return nil, false
+			}
+		}
+	}
+
+	return tokens, true
+}
+
+func matchSubject(pattern []string, tokens []string) bool {
+
+	for i, p := range pattern {
+
+		if p == ">" {
+			return len(tokens) > i
+		}
+
+		if i >= len(tokens) {
+			return false
+		}
+
+		if p != "*" && p != tokens[i] {
+			return false
+		}
+	}
+
+	return len(pattern) == len(tokens)
+}
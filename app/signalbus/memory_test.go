@@ -0,0 +1,210 @@
+package signalbus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	app "twist-commander/app/interface"
+)
+
+func TestParseSubject(t *testing.T) {
+
+	tests := []struct {
+		subject   string
+		wildcards bool
+		valid     bool
+	}{
+		{"twist.transaction.a.eventEmitted", false, true},
+		{"twist.transaction.*.eventEmitted", true, true},
+		{"twist.transaction.>", true, true},
+		{"twist.transaction.*.eventEmitted", false, false},
+		{"twist.transaction.>", false, false},
+		{"twist.>.eventEmitted", true, false},
+		{"twist..transaction", true, false},
+		{".twist", true, false},
+		{"twist.", true, false},
+		{"twist transaction", true, false},
+		{"", true, false},
+	}
+
+	for _, test := range tests {
+		_, ok := parseSubject(test.subject, test.wildcards)
+		if ok != test.valid {
+			t.Errorf("parseSubject(%q, %v) = %v, expected %v", test.subject, test.wildcards, ok, test.valid)
+		}
+	}
+}
+
+func TestMatchSubject(t *testing.T) {
+
+	tests := []struct {
+		pattern string
+		subject string
+		match   bool
+	}{
+		{"a.b.c", "a.b.c", true},
+		{"a.b.c", "a.b.d", false},
+		{"a.b", "a.b.c", false},
+		{"a.b.c", "a.b", false},
+		{"a.*.c", "a.b.c", true},
+		{"a.*.c", "a.b.d", false},
+		{"a.*", "a.b.c", false},
+		{"*.*.*", "a.b.c", true},
+		{"a.>", "a.b", true},
+		{"a.>", "a.b.c.d", true},
+		{"a.>", "a", false},
+		{"a.*.>", "a.b.c", true},
+		{"a.*.>", "a.b", false},
+		{">", "a", true},
+	}
+
+	for _, test := range tests {
+		pattern, _ := parseSubject(test.pattern, true)
+		tokens, _ := parseSubject(test.subject, false)
+
+		if matchSubject(pattern, tokens) != test.match {
+			t.Errorf("matchSubject(%q, %q) != %v", test.pattern, test.subject, test.match)
+		}
+	}
+}
+
+func createTestBus(t *testing.T) *MemoryBus {
+
+	mb := CreateMemoryBus(Options{})
+	if err := mb.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	return mb
+}
+
+func TestMemoryBusDeliversInOrder(t *testing.T) {
+
+	mb := createTestBus(t)
+	defer mb.Close()
+
+	received := make(chan string, 3)
+	_, err := mb.Watch("twist.transaction.*.eventEmitted", "test", func(msg *app.Message) {
+		received <- string(msg.Data)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mb.Emit("twist.transaction.a.eventEmitted", []byte("1"))
+	mb.Emit("twist.transaction.a.cmdReceived", []byte("ignored"))
+	mb.Emit("twist.transaction.b.eventEmitted", []byte("2"))
+
+	for _, expected := range []string{"1", "2"} {
+		select {
+		case data := <-received:
+			if data != expected {
+				t.Fatalf("Expected %q, got %q", expected, data)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Message was not delivered")
+		}
+	}
+
+	if err := mb.Emit("twist.transaction.*.eventEmitted", nil); err != ErrInvalidSubject {
+		t.Errorf("Expected invalid subject for wildcard publish, got %v", err)
+	}
+}
+
+func TestDispatchReportsMatch(t *testing.T) {
+
+	d := createDispatcher()
+	defer d.stopAll()
+
+	if _, err := d.subscribe("twist.transaction.*.eventEmitted", func(*app.Message) {}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Streaming bus leaves signals nobody watches unacknowledged
+	tokens, _ := parseSubject("twist.transaction.a.eventEmitted", false)
+	if !d.dispatch(&app.Message{Subject: "twist.transaction.a.eventEmitted"}, tokens) {
+		t.Error("Expected watched signal to match")
+	}
+
+	tokens, _ = parseSubject("twist.transaction.a.cmdReceived", false)
+	if d.dispatch(&app.Message{Subject: "twist.transaction.a.cmdReceived"}, tokens) {
+		t.Error("Expected signal nobody watches not to match")
+	}
+}
+
+func TestMemoryBusRequest(t *testing.T) {
+
+	mb := createTestBus(t)
+	defer mb.Close()
+
+	_, err := mb.Watch("service.echo", "test", func(msg *app.Message) {
+		mb.Emit(msg.Reply, append([]byte("echo "), msg.Data...))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	res, err := mb.Request(ctx, "service.echo", []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(res) != "echo hello" {
+		t.Errorf("Unexpected reply %q", res)
+	}
+
+	// Inbox is gone once request is completed
+	if mb.SubscriptionCount() != 1 {
+		t.Errorf("Expected 1 subscription, got %d", mb.SubscriptionCount())
+	}
+}
+
+func TestMemoryBusRequestWithoutResponder(t *testing.T) {
+
+	mb := createTestBus(t)
+	defer mb.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := mb.Request(ctx, "service.nobody", nil)
+	if err != context.DeadlineExceeded {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestMemoryBusClose(t *testing.T) {
+
+	mb := createTestBus(t)
+	defer mb.Close()
+
+	unavailable := make(chan struct{})
+	mb.NotifyUnavailable(func() {
+		close(unavailable)
+	})
+
+	sub, err := mb.Watch("twist.>", "test", func(*app.Message) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mb.Close()
+
+	select {
+	case <-unavailable:
+	case <-time.After(time.Second):
+		t.Fatal("Unavailable function was not called")
+	}
+
+	if sub.IsValid() {
+		t.Error("Subscription is still valid after close")
+	}
+
+	if err := mb.Emit("twist.a", nil); err != ErrClosed {
+		t.Errorf("Expected closed bus, got %v", err)
+	}
+}
@@ -0,0 +1,178 @@
+package signalbus
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	app "twist-commander/app/interface"
+)
+
+type SubscriptionInfo struct {
+	Subject   string    `json:"subject"`
+	Creator   string    `json:"creator"`
+	CreatedAt time.Time `json:"createdAt"`
+	Age       string    `json:"age"`
+	Leaked    bool      `json:"leaked"`
+
+	sub      app.Subscription
+	reported bool
+}
+
+// Registry keeps track of active subscriptions, so leaked ones can be found
+// and everything can be cleaned up on close
+type Registry struct {
+	mutex         sync.Mutex
+	subscriptions map[app.Subscription]*SubscriptionInfo
+	leakThreshold time.Duration
+}
+
+func CreateRegistry(leakThreshold time.Duration) *Registry {
+	return &Registry{
+		subscriptions: make(map[app.Subscription]*SubscriptionInfo),
+		leakThreshold: leakThreshold,
+	}
+}
+
+func (r *Registry) Add(sub app.Subscription, creator string) {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.subscriptions[sub] = &SubscriptionInfo{
+		Subject:   sub.Subject(),
+		Creator:   creator,
+		CreatedAt: time.Now(),
+		sub:       sub,
+	}
+}
+
+func (r *Registry) Remove(sub app.Subscription) {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.subscriptions, sub)
+}
+
+func (r *Registry) Count() int {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return len(r.subscriptions)
+}
+
+// List returns snapshot of active subscriptions, oldest first
+func (r *Registry) List() []SubscriptionInfo {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	list := make([]SubscriptionInfo, 0, len(r.subscriptions))
+	for _, s := range r.subscriptions {
+		age := now.Sub(s.CreatedAt)
+		list = append(list, SubscriptionInfo{
+			Subject:   s.Subject,
+			Creator:   s.Creator,
+			CreatedAt: s.CreatedAt,
+			Age:       age.Round(time.Second).String(),
+			Leaked:    r.isLeaked(age),
+		})
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt.Before(list[j].CreatedAt)
+	})
+
+	return list
+}
+
+func (r *Registry) isLeaked(age time.Duration) bool {
+	return r.leakThreshold > 0 && age > r.leakThreshold
+}
+
+// DetectLeaks sweeps registry periodically until stop is closed
+func (r *Registry) DetectLeaks(stop <-chan struct{}) {
+
+	if r.leakThreshold <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(r.leakThreshold / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			r.Sweep()
+		}
+	}
+}
+
+// Sweep forgets subscriptions which were closed behind our back, and reports
+// every leaked subscription once
+func (r *Registry) Sweep() int {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	leaked := 0
+	for key, s := range r.subscriptions {
+
+		if !s.sub.IsValid() {
+			delete(r.subscriptions, key)
+			continue
+		}
+
+		age := time.Since(s.CreatedAt)
+		if !r.isLeaked(age) {
+			continue
+		}
+
+		leaked++
+
+		if s.reported {
+			continue
+		}
+
+		s.reported = true
+
+		log.WithFields(log.Fields{
+			"subject": s.Subject,
+			"creator": s.Creator,
+			"age":     age.Round(time.Second).String(),
+		}).Warn("Subscription might be leaked")
+	}
+
+	return leaked
+}
+
+// UnsubscribeAll removes every subscription from signal server
+func (r *Registry) UnsubscribeAll() {
+
+	r.mutex.Lock()
+	subs := r.subscriptions
+	r.subscriptions = make(map[app.Subscription]*SubscriptionInfo)
+	r.mutex.Unlock()
+
+	for _, s := range subs {
+		if err := s.sub.Unsubscribe(); err != nil && s.sub.IsValid() {
+			log.WithFields(log.Fields{
+				"subject": s.Subject,
+				"error":   err,
+			}).Warn("Failed to unsubscribe")
+		}
+	}
+
+	if len(subs) > 0 {
+		log.WithFields(log.Fields{
+			"count": len(subs),
+		}).Info("Unsubscribed from signal server")
+	}
+}
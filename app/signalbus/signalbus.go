@@ -1,67 +1,326 @@
 package signalbus
 
 import (
+	"context"
+	"sync"
+	"time"
+
 	nats "github.com/nats-io/nats.go"
 	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"twist-commander/app/breaker"
+	app "twist-commander/app/interface"
+)
+
+// Connection states
+const (
+	StateConnected    = "connected"
+	StateReconnecting = "reconnecting"
+	StateDisconnected = "disconnected"
+	StateClosed       = "closed"
 )
 
+var ErrUnavailable = status.Error(codes.Unavailable, "Signal server is not available")
+
+type Options struct {
+
+	// Negative value means reconnecting forever
+	MaxReconnects int
+	ReconnectWait time.Duration
+
+	// Messages are buffered up to this size while reconnecting
+	ReconnectBufSize int
+
+	// Signal bus is considered unavailable once outage lasts longer than this
+	OutageGrace time.Duration
+
+	// Subscriptions older than this are reported as leaked, zero disables it
+	LeakThreshold time.Duration
+}
+
 type SignalBus struct {
 	host       string
 	clientName string
+	options    Options
 	client     *nats.Conn
+	breaker    *breaker.Breaker
+	registry   *Registry
+	stop       chan struct{}
+	closeOnce  sync.Once
+
+	mutex          sync.Mutex
+	disconnectedAt time.Time
+	outageTimer    *time.Timer
+	unavailableFns []func()
 }
 
-func CreateConnector(host string, clientName string) *SignalBus {
+func CreateConnector(host string, clientName string, b *breaker.Breaker, opts Options) *SignalBus {
+
+	if opts.ReconnectWait <= 0 {
+		opts.ReconnectWait = nats.DefaultReconnectWait
+	}
+
+	if opts.ReconnectBufSize <= 0 {
+		opts.ReconnectBufSize = nats.DefaultReconnectBufSize
+	}
+
+	if opts.OutageGrace <= 0 {
+		opts.OutageGrace = 5 * time.Second
+	}
+
 	return &SignalBus{
 		host:       host,
 		clientName: clientName,
+		options:    opts,
+		breaker:    b,
+		registry:   CreateRegistry(opts.LeakThreshold),
+		stop:       make(chan struct{}),
 	}
 }
 
 func (sb *SignalBus) Connect() error {
 
 	log.WithFields(log.Fields{
-		"host":       sb.host,
-		"clientName": sb.clientName,
+		"host":          sb.host,
+		"clientName":    sb.clientName,
+		"maxReconnects": sb.options.MaxReconnects,
+		"reconnectWait": sb.options.ReconnectWait,
 	}).Info("Connecting to signal server")
 
 	// Connect to signal server
-	nc, err := nats.Connect(sb.host, nats.Name(sb.clientName))
+	nc, err := nats.Connect(sb.host,
+		nats.Name(sb.clientName),
+		nats.MaxReconnects(sb.options.MaxReconnects),
+		nats.ReconnectWait(sb.options.ReconnectWait),
+		nats.ReconnectBufSize(sb.options.ReconnectBufSize),
+		nats.DisconnectErrHandler(sb.onDisconnected),
+		nats.ReconnectHandler(sb.onReconnected),
+		nats.ClosedHandler(sb.onClosed),
+	)
 	if err != nil {
 		return err
 	}
 
 	sb.client = nc
 
-	nc.SetReconnectHandler(func(rcb *nats.Conn) {
-		log.Info("Reconnecting to signal server ...")
-	})
+	go sb.registry.DetectLeaks(sb.stop)
 
 	return nil
 }
 
+func (sb *SignalBus) onDisconnected(nc *nats.Conn, err error) {
+
+	log.WithFields(log.Fields{
+		"error": err,
+	}).Warn("Disconnected from signal server")
+
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+
+	sb.disconnectedAt = time.Now()
+
+	// Short outage is covered by reconnect buffer, otherwise everyone who is
+	// waiting for signals should give up
+	if sb.outageTimer != nil {
+		sb.outageTimer.Stop()
+	}
+
+	sb.outageTimer = time.AfterFunc(sb.options.OutageGrace, func() {
+		if !sb.IsAvailable() {
+			log.Error("Signal server is unavailable")
+			sb.breaker.Trip()
+			sb.notifyUnavailable()
+		}
+	})
+}
+
+func (sb *SignalBus) onReconnected(nc *nats.Conn) {
+
+	log.WithFields(log.Fields{
+		"host": nc.ConnectedUrl(),
+	}).Info("Reconnected to signal server")
+
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+
+	sb.disconnectedAt = time.Time{}
+
+	if sb.outageTimer != nil {
+		sb.outageTimer.Stop()
+		sb.outageTimer = nil
+	}
+
+	sb.breaker.Reset()
+}
+
+func (sb *SignalBus) onClosed(nc *nats.Conn) {
+
+	log.Warn("Connection to signal server was closed")
+
+	sb.mutex.Lock()
+	if sb.outageTimer != nil {
+		sb.outageTimer.Stop()
+		sb.outageTimer = nil
+	}
+	sb.mutex.Unlock()
+
+	sb.notifyUnavailable()
+}
+
+// NotifyUnavailable registers a function which is called when signal bus
+// becomes unavailable
+func (sb *SignalBus) NotifyUnavailable(fn func()) {
+
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+
+	sb.unavailableFns = append(sb.unavailableFns, fn)
+}
+
+func (sb *SignalBus) notifyUnavailable() {
+
+	sb.mutex.Lock()
+	fns := append([]func(){}, sb.unavailableFns...)
+	sb.mutex.Unlock()
+
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+// State returns state of connection to signal server
+func (sb *SignalBus) State() string {
+
+	if sb.client == nil {
+		return StateDisconnected
+	}
+
+	switch sb.client.Status() {
+	case nats.CONNECTED:
+		return StateConnected
+	case nats.RECONNECTING:
+		return StateReconnecting
+	case nats.CLOSED:
+		return StateClosed
+	}
+
+	return StateDisconnected
+}
+
+// IsAvailable returns false if signal server is gone, or outage has lasted
+// longer than grace period
+func (sb *SignalBus) IsAvailable() bool {
+
+	switch sb.State() {
+	case StateConnected:
+		return true
+	case StateReconnecting:
+		sb.mutex.Lock()
+		defer sb.mutex.Unlock()
+
+		return sb.disconnectedAt.IsZero() || time.Since(sb.disconnectedAt) < sb.options.OutageGrace
+	}
+
+	return false
+}
+
 func (sb *SignalBus) Close() {
-	sb.client.Close()
+
+	if sb.client == nil {
+		return
+	}
+
+	sb.closeOnce.Do(func() {
+		close(sb.stop)
+
+		sb.registry.UnsubscribeAll()
+		sb.client.Close()
+	})
 }
 
 func (sb *SignalBus) Emit(topic string, data []byte) error {
 
-	if err := sb.client.Publish(topic, data); err != nil {
+	// Fail fast while signal server is degraded
+	if err := sb.breaker.Allow(); err != nil {
 		return err
 	}
 
-	return nil
+	// Publish is buffered while reconnecting and hardly ever fails, so health
+	// of signal server is taken from connection state
+	if !sb.IsAvailable() {
+		sb.breaker.Failure()
+		return ErrUnavailable
+	}
+
+	sb.breaker.Success()
+
+	return sb.client.Publish(topic, data)
 }
 
-func (sb *SignalBus) Watch(topic string, fn func(*nats.Msg)) (*nats.Subscription, error) {
+// Watch subscribes to topic on behalf of creator, which is recorded for
+// finding leaked subscriptions
+func (sb *SignalBus) Watch(topic string, creator string, fn func(*app.Message)) (app.Subscription, error) {
 
 	// Subscribe
-	sub, err := sb.client.Subscribe(topic, fn)
+	s, err := sb.client.Subscribe(topic, func(msg *nats.Msg) {
+		fn(&app.Message{
+			Subject: msg.Subject,
+			Reply:   msg.Reply,
+			Data:    msg.Data,
+		})
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	sub := &natsSubscription{sub: s}
+
 	// Add to subscription list
+	sb.registry.Add(sub, creator)
 
 	return sub, nil
 }
+
+func (sb *SignalBus) Unwatch(sub app.Subscription) error {
+
+	sb.registry.Remove(sub)
+
+	return sub.Unsubscribe()
+}
+
+func (sb *SignalBus) SubscriptionCount() int {
+	return sb.registry.Count()
+}
+
+// Subscriptions returns active subscriptions
+func (sb *SignalBus) Subscriptions() []SubscriptionInfo {
+	return sb.registry.List()
+}
+
+func (sb *SignalBus) Request(ctx context.Context, topic string, data []byte) ([]byte, error) {
+
+	msg, err := sb.client.RequestWithContext(ctx, topic, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return msg.Data, nil
+}
+
+type natsSubscription struct {
+	sub *nats.Subscription
+}
+
+func (s *natsSubscription) Subject() string {
+	return s.sub.Subject
+}
+
+func (s *natsSubscription) IsValid() bool {
+	return s.sub.IsValid()
+}
+
+func (s *natsSubscription) Unsubscribe() error {
+	return s.sub.Unsubscribe()
+}
@@ -0,0 +1,369 @@
+package signalbus
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	stan "github.com/nats-io/stan.go"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	app "twist-commander/app/interface"
+	pb "twist-commander/pb"
+)
+
+var (
+	ErrStreamingUnavailable = status.Error(codes.Unavailable, "Streaming server is not connected")
+	ErrClientIDRequired     = errors.New("Stable client ID is required for streaming")
+)
+
+const DefaultRedeliveryWindow = 2 * time.Minute
+
+// Durable subscription belongs to client ID, so one name serves every instance
+const durableName = "commander"
+
+type StreamingOptions struct {
+	ClusterID string
+
+	// Stable ID of instance, its durable subscription is resumed after restart
+	ClientID string
+
+	// Every signal goes through this channel, so number of channels does not
+	// grow with transactions
+	Channel string
+
+	// Time to wait for acknowledgement of published message
+	PubAckWait time.Duration
+
+	// Unacknowledged message is redelivered after this
+	AckWait     time.Duration
+	MaxInflight int
+
+	// Signals nobody watches are left unacknowledged until they are this old,
+	// so watchers which appear meanwhile still receive them
+	RedeliveryWindow time.Duration
+
+	PingInterval int
+	PingMaxOut   int
+}
+
+// StreamingBus publishes signals to NATS Streaming and consumes them with a
+// durable subscription, so commands and events survive restarts of commander
+// and runner. Streaming server has no wildcards, so every signal is wrapped
+// with its subject and sent through one channel, which each instance consumes
+// as a whole and dispatches to its watchers. Request/reply keeps using core
+// NATS.
+type StreamingBus struct {
+	*SignalBus
+
+	streaming  StreamingOptions
+	dispatcher *dispatcher
+
+	mutex  sync.Mutex
+	conn   stan.Conn
+	sub    stan.Subscription
+	closed bool
+}
+
+func CreateStreamingBus(sb *SignalBus, opts StreamingOptions) *StreamingBus {
+
+	if opts.ClusterID == "" {
+		opts.ClusterID = "test-cluster"
+	}
+
+	if opts.PubAckWait <= 0 {
+		opts.PubAckWait = stan.DefaultAckWait
+	}
+
+	if opts.AckWait <= 0 {
+		opts.AckWait = stan.DefaultAckWait
+	}
+
+	if opts.MaxInflight <= 0 {
+		opts.MaxInflight = stan.DefaultMaxInflight
+	}
+
+	if opts.RedeliveryWindow <= 0 {
+		opts.RedeliveryWindow = DefaultRedeliveryWindow
+	}
+
+	if opts.PingInterval <= 0 {
+		opts.PingInterval = stan.DefaultPingInterval
+	}
+
+	if opts.PingMaxOut <= 0 {
+		opts.PingMaxOut = stan.DefaultPingMaxOut
+	}
+
+	return &StreamingBus{
+		SignalBus:  sb,
+		streaming:  opts,
+		dispatcher: createDispatcher(),
+	}
+}
+
+func (bus *StreamingBus) Connect() error {
+
+	if bus.streaming.Channel == "" {
+		return errors.New("Channel is required for streaming")
+	}
+
+	// Durable subscription of generated ID could never be resumed
+	if bus.streaming.ClientID == "" {
+		return ErrClientIDRequired
+	}
+
+	err := bus.SignalBus.Connect()
+	if err != nil {
+		return err
+	}
+
+	return bus.connectStreaming()
+}
+
+func (bus *StreamingBus) connectStreaming() error {
+
+	log.WithFields(log.Fields{
+		"clusterID": bus.streaming.ClusterID,
+		"clientID":  bus.streaming.ClientID,
+		"channel":   bus.streaming.Channel,
+	}).Info("Connecting to streaming server")
+
+	conn, err := stan.Connect(bus.streaming.ClusterID, bus.streaming.ClientID,
+		stan.NatsConn(bus.client),
+		stan.PubAckWait(bus.streaming.PubAckWait),
+		stan.Pings(bus.streaming.PingInterval, bus.streaming.PingMaxOut),
+		stan.SetConnectionLostHandler(bus.onConnectionLost),
+	)
+	if err != nil {
+		return err
+	}
+
+	// New durable subscription starts now, while existing one continues where
+	// it stopped, so history of the channel is never replayed
+	sub, err := conn.Subscribe(bus.streaming.Channel, bus.handleMessage,
+		stan.DurableName(durableName),
+		stan.StartAtTime(time.Now()),
+		stan.SetManualAckMode(),
+		stan.AckWait(bus.streaming.AckWait),
+		stan.MaxInflight(bus.streaming.MaxInflight),
+	)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+
+	if bus.closed {
+		conn.Close()
+		return ErrClosed
+	}
+
+	bus.conn = conn
+	bus.sub = sub
+
+	return nil
+}
+
+// handleMessage dispatches signal to watchers of its subject. Signal nobody
+// is watching is left unacknowledged, so it is redelivered once a watcher was
+// created, after reconnecting or restarting. It is acknowledged after the
+// redelivery window, since it most likely belongs to another instance, and
+// would take a slot of max in-flight messages forever.
+func (bus *StreamingBus) handleMessage(msg *stan.Msg) {
+
+	var signal pb.Signal
+	err := proto.Unmarshal(msg.Data, &signal)
+	tokens, ok := parseSubject(signal.Subject, false)
+
+	if err != nil || !ok {
+		log.WithFields(log.Fields{
+			"sequence": msg.Sequence,
+		}).Warn("Dropped malformed signal")
+	} else {
+		matched := bus.dispatcher.dispatch(&app.Message{
+			Subject: signal.Subject,
+			Data:    signal.Data,
+		}, tokens)
+
+		if !matched && time.Since(time.Unix(0, msg.Timestamp)) < bus.streaming.RedeliveryWindow {
+			return
+		}
+	}
+
+	if err := msg.Ack(); err != nil {
+		log.WithFields(log.Fields{
+			"sequence": msg.Sequence,
+			"error":    err,
+		}).Warn("Failed to acknowledge message")
+	}
+}
+
+func (bus *StreamingBus) onConnectionLost(conn stan.Conn, reason error) {
+
+	log.WithFields(log.Fields{
+		"error": reason,
+	}).Error("Connection to streaming server was lost")
+
+	bus.mutex.Lock()
+	bus.conn = nil
+	bus.sub = nil
+	closed := bus.closed
+	bus.mutex.Unlock()
+
+	if closed {
+		return
+	}
+
+	bus.notifyUnavailable()
+
+	go bus.reconnectStreaming()
+}
+
+func (bus *StreamingBus) reconnectStreaming() {
+
+	for {
+		select {
+		case <-bus.stop:
+			return
+		case <-time.After(bus.options.ReconnectWait):
+		}
+
+		err := bus.connectStreaming()
+		if err == nil {
+			log.Info("Reconnected to streaming server")
+			return
+		}
+
+		if err == ErrClosed {
+			return
+		}
+
+		log.WithFields(log.Fields{
+			"error": err,
+		}).Warn("Failed to reconnect to streaming server")
+	}
+}
+
+func (bus *StreamingBus) getConn() stan.Conn {
+
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+
+	return bus.conn
+}
+
+// State reports reconnecting while only streaming connection is gone
+func (bus *StreamingBus) State() string {
+
+	state := bus.SignalBus.State()
+	if state == StateConnected && bus.getConn() == nil {
+		return StateReconnecting
+	}
+
+	return state
+}
+
+func (bus *StreamingBus) IsAvailable() bool {
+	return bus.getConn() != nil && bus.SignalBus.IsAvailable()
+}
+
+func (bus *StreamingBus) Close() {
+
+	bus.mutex.Lock()
+	if bus.closed {
+		bus.mutex.Unlock()
+		return
+	}
+
+	bus.closed = true
+	conn := bus.conn
+	sub := bus.sub
+	bus.conn = nil
+	bus.sub = nil
+	bus.mutex.Unlock()
+
+	// Durable subscription is kept for the next run, closing connection only
+	// stops delivery
+	if sub != nil {
+		if err := sub.Close(); err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+			}).Warn("Failed to close durable subscription")
+		}
+	}
+
+	if conn != nil {
+		if err := conn.Close(); err != nil {
+			log.WithFields(log.Fields{
+				"error": err,
+			}).Warn("Failed to close connection to streaming server")
+		}
+	}
+
+	bus.registry.UnsubscribeAll()
+	bus.dispatcher.stopAll()
+	bus.SignalBus.Close()
+}
+
+// Emit returns once streaming server has stored the message
+func (bus *StreamingBus) Emit(topic string, data []byte) error {
+
+	conn := bus.getConn()
+	if conn == nil {
+		return ErrStreamingUnavailable
+	}
+
+	// Fail fast while signal server is degraded
+	if err := bus.breaker.Allow(); err != nil {
+		return err
+	}
+
+	signal, err := proto.Marshal(&pb.Signal{
+		Subject: topic,
+		Data:    data,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := conn.Publish(bus.streaming.Channel, signal); err != nil {
+		bus.breaker.Failure()
+		return err
+	}
+
+	bus.breaker.Success()
+
+	return nil
+}
+
+// Watch starts dispatching signals of topic to fn. Signals come from the
+// subscription of the channel, which is acknowledged once they are queued.
+func (bus *StreamingBus) Watch(topic string, creator string, fn func(*app.Message)) (app.Subscription, error) {
+
+	if bus.getConn() == nil {
+		return nil, ErrStreamingUnavailable
+	}
+
+	sub, err := bus.dispatcher.subscribe(topic, fn)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add to subscription list
+	bus.registry.Add(sub, creator)
+
+	return sub, nil
+}
+
+func (bus *StreamingBus) Unwatch(sub app.Subscription) error {
+
+	bus.registry.Remove(sub)
+
+	return sub.Unsubscribe()
+}
@@ -0,0 +1,240 @@
+package store
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	bolt "go.etcd.io/bbolt"
+
+	app "twist-commander/app/interface"
+)
+
+var transactionsBucket = []byte("transactions")
+
+// FileStore keeps transactions in an embedded database file, so that they
+// survive restarts of commander. Finished ones are dropped once retention
+// period has passed, and unfinished ones once they were abandoned for longer
+// than abandoned period.
+type FileStore struct {
+	path      string
+	db        *bolt.DB
+	retention time.Duration
+	abandoned time.Duration
+
+	mutex    sync.Mutex
+	prunedAt time.Time
+}
+
+// CreateFileStore creates store which keeps transactions forever if both
+// periods are zero
+func CreateFileStore(path string, retention time.Duration, abandoned time.Duration) *FileStore {
+	return &FileStore{
+		path:      path,
+		retention: retention,
+		abandoned: abandoned,
+	}
+}
+
+func (fs *FileStore) Open() error {
+
+	if fs.path == "" {
+		return errors.New("Path of transaction store is required")
+	}
+
+	log.WithFields(log.Fields{
+		"path": fs.path,
+	}).Info("Opening transaction store")
+
+	// File is locked while another instance has it open
+	db, err := bolt.Open(fs.path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return err
+	}
+
+	err = db.Update(func(btx *bolt.Tx) error {
+		_, err := btx.CreateBucketIfNotExists(transactionsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return err
+	}
+
+	fs.db = db
+
+	return nil
+}
+
+func (fs *FileStore) Create(tx *app.Transaction) error {
+
+	fs.prune(time.Now())
+
+	return fs.db.Update(func(btx *bolt.Tx) error {
+		bucket := btx.Bucket(transactionsBucket)
+
+		if bucket.Get([]byte(tx.TransactionID)) != nil {
+			return ErrExists
+		}
+
+		initTransaction(tx)
+
+		data, err := encode(tx)
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put([]byte(tx.TransactionID), data)
+	})
+}
+
+func (fs *FileStore) Get(transactionID string) (*app.Transaction, error) {
+
+	var tx *app.Transaction
+
+	err := fs.db.View(func(btx *bolt.Tx) error {
+		data := btx.Bucket(transactionsBucket).Get([]byte(transactionID))
+		if data == nil {
+			return ErrNotFound
+		}
+
+		var err error
+		tx, err = decode(data)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+// List returns transactions in order of creation
+func (fs *FileStore) List() ([]*app.Transaction, error) {
+
+	list := make([]*app.Transaction, 0)
+
+	err := fs.db.View(func(btx *bolt.Tx) error {
+		return btx.Bucket(transactionsBucket).ForEach(func(k, v []byte) error {
+			tx, err := decode(v)
+			if err != nil {
+				return err
+			}
+
+			list = append(list, tx)
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt.Before(list[j].CreatedAt)
+	})
+
+	return list, nil
+}
+
+func (fs *FileStore) Close() error {
+
+	if fs.db == nil {
+		return nil
+	}
+
+	return fs.db.Close()
+}
+
+func (fs *FileStore) Update(transactionID string, fn func(*app.Transaction)) error {
+
+	return fs.db.Update(func(btx *bolt.Tx) error {
+		bucket := btx.Bucket(transactionsBucket)
+
+		data := bucket.Get([]byte(transactionID))
+		if data == nil {
+			return ErrNotFound
+		}
+
+		tx, err := decode(data)
+		if err != nil {
+			return err
+		}
+
+		fn(tx)
+		tx.UpdatedAt = time.Now()
+
+		data, err = encode(tx)
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put([]byte(transactionID), data)
+	})
+}
+
+// prune drops finished transactions which are older than retention period,
+// and abandoned ones. Transactions left by the previous run are pruned by the
+// first call.
+func (fs *FileStore) prune(now time.Time) {
+
+	interval := pruneInterval(fs.retention, fs.abandoned)
+	if interval <= 0 {
+		return
+	}
+
+	fs.mutex.Lock()
+	if now.Sub(fs.prunedAt) < interval {
+		fs.mutex.Unlock()
+		return
+	}
+
+	fs.prunedAt = now
+	fs.mutex.Unlock()
+
+	pruned := 0
+
+	err := fs.db.Update(func(btx *bolt.Tx) error {
+		bucket := btx.Bucket(transactionsBucket)
+
+		// Keys cannot be deleted while iterating
+		var expired [][]byte
+		err := bucket.ForEach(func(k, v []byte) error {
+			tx, err := decode(v)
+			if err == nil && isExpired(tx, now, fs.retention, fs.abandoned) {
+				expired = append(expired, k)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, key := range expired {
+			if err := bucket.Delete(key); err != nil {
+				return err
+			}
+		}
+
+		pruned = len(expired)
+
+		return nil
+	})
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err,
+		}).Warn("Failed to prune transaction store")
+
+		return
+	}
+
+	if pruned > 0 {
+		log.WithFields(log.Fields{
+			"count": pruned,
+		}).Info("Pruned expired transactions")
+	}
+}
@@ -0,0 +1,141 @@
+package store
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	app "twist-commander/app/interface"
+)
+
+// MemoryStore keeps transactions until commander stops, finished ones are
+// dropped once retention period has passed, and unfinished ones once they were
+// abandoned for longer than abandoned period
+type MemoryStore struct {
+	mutex        sync.Mutex
+	transactions map[string][]byte
+	retention    time.Duration
+	abandoned    time.Duration
+	prunedAt     time.Time
+}
+
+// CreateMemoryStore creates store which keeps transactions forever if both
+// periods are zero
+func CreateMemoryStore(retention time.Duration, abandoned time.Duration) *MemoryStore {
+	return &MemoryStore{
+		transactions: make(map[string][]byte),
+		retention:    retention,
+		abandoned:    abandoned,
+		prunedAt:     time.Now(),
+	}
+}
+
+func (ms *MemoryStore) Create(tx *app.Transaction) error {
+
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	ms.prune(time.Now())
+
+	if _, ok := ms.transactions[tx.TransactionID]; ok {
+		return ErrExists
+	}
+
+	initTransaction(tx)
+
+	data, err := encode(tx)
+	if err != nil {
+		return err
+	}
+
+	ms.transactions[tx.TransactionID] = data
+
+	return nil
+}
+
+func (ms *MemoryStore) Get(transactionID string) (*app.Transaction, error) {
+
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	data, ok := ms.transactions[transactionID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return decode(data)
+}
+
+// List returns transactions in order of creation
+func (ms *MemoryStore) List() ([]*app.Transaction, error) {
+
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	list := make([]*app.Transaction, 0, len(ms.transactions))
+	for _, data := range ms.transactions {
+		tx, err := decode(data)
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, tx)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt.Before(list[j].CreatedAt)
+	})
+
+	return list, nil
+}
+
+func (ms *MemoryStore) Close() error {
+	return nil
+}
+
+func (ms *MemoryStore) Update(transactionID string, fn func(*app.Transaction)) error {
+
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	data, ok := ms.transactions[transactionID]
+	if !ok {
+		return ErrNotFound
+	}
+
+	tx, err := decode(data)
+	if err != nil {
+		return err
+	}
+
+	fn(tx)
+	tx.UpdatedAt = time.Now()
+
+	data, err = encode(tx)
+	if err != nil {
+		return err
+	}
+
+	ms.transactions[transactionID] = data
+
+	return nil
+}
+
+// prune drops finished transactions which are older than retention period,
+// and abandoned ones
+func (ms *MemoryStore) prune(now time.Time) {
+
+	interval := pruneInterval(ms.retention, ms.abandoned)
+	if interval <= 0 || now.Sub(ms.prunedAt) < interval {
+		return
+	}
+
+	ms.prunedAt = now
+
+	for transactionID, data := range ms.transactions {
+		tx, err := decode(data)
+		if err == nil && isExpired(tx, now, ms.retention, ms.abandoned) {
+			delete(ms.transactions, transactionID)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package store
+
+import (
+	"encoding/json"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	app "twist-commander/app/interface"
+)
+
+// Backends of transaction store
+const (
+	BackendMemory = "memory"
+	BackendFile   = "file"
+)
+
+// States of transaction which are not outcomes reported by runner
+const (
+	StateCreated    = "Created"
+	StateAssigned   = "Assigned"
+	StateFailed     = "Failed"
+	StateConfirming = "Confirming"
+	StateCanceling  = "Canceling"
+	StateResolved   = "Resolved"
+)
+
+var (
+	ErrNotFound = status.Error(codes.NotFound, "Transaction not found")
+	ErrExists   = status.Error(codes.AlreadyExists, "Transaction exists already")
+)
+
+// isFinished tells whether transaction reached a state it never leaves on its
+// own
+func isFinished(tx *app.Transaction) bool {
+	return tx.Outcome != "" || tx.State == StateFailed || tx.State == StateResolved
+}
+
+// isExpired tells whether transaction has finished before retention period,
+// or was abandoned unfinished for longer than abandoned period. Zero period
+// keeps transactions forever.
+func isExpired(tx *app.Transaction, now time.Time, retention time.Duration, abandoned time.Duration) bool {
+
+	period := abandoned
+	if isFinished(tx) {
+		period = retention
+	}
+
+	return period > 0 && tx.UpdatedAt.Before(now.Add(-period))
+}
+
+// pruneInterval is half of the shorter period, or zero if nothing expires
+func pruneInterval(retention time.Duration, abandoned time.Duration) time.Duration {
+
+	period := retention
+	if period <= 0 || (abandoned > 0 && abandoned < period) {
+		period = abandoned
+	}
+
+	if period <= 0 {
+		return 0
+	}
+
+	return period / 2
+}
+
+// Both backends keep transactions encoded, so that callers never share them
+func encode(tx *app.Transaction) ([]byte, error) {
+	return json.Marshal(tx)
+}
+
+func decode(data []byte) (*app.Transaction, error) {
+
+	var tx app.Transaction
+	err := json.Unmarshal(data, &tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tx, nil
+}
+
+func initTransaction(tx *app.Transaction) {
+
+	now := time.Now()
+
+	if tx.State == "" {
+		tx.State = StateCreated
+	}
+
+	if tx.CreatedAt.IsZero() {
+		tx.CreatedAt = now
+	}
+
+	tx.UpdatedAt = now
+	tx.Transitions = []app.Transition{
+		{State: tx.State, At: now},
+	}
+}
+
+// ApplyTransition moves transaction to state, keeping the history
+func ApplyTransition(tx *app.Transaction, state string, reason string) {
+
+	now := time.Now()
+
+	tx.State = state
+	tx.UpdatedAt = now
+	tx.Transitions = append(tx.Transitions, app.Transition{
+		State:  state,
+		Reason: reason,
+		At:     now,
+	})
+}
+
+// ApplyOutcome records final result of transaction
+func ApplyOutcome(tx *app.Transaction, outcome string, detail string) {
+
+	tx.Outcome = outcome
+	tx.OutcomeDetail = detail
+
+	ApplyTransition(tx, outcome, "")
+}
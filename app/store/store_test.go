@@ -0,0 +1,234 @@
+package store
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	app "twist-commander/app/interface"
+)
+
+const (
+	testRetention = time.Hour
+	testAbandoned = 4 * time.Hour
+)
+
+type testBackend struct {
+	name  string
+	open  func(t *testing.T) (app.TransactionStore, func())
+	prune func(app.TransactionStore, time.Time)
+}
+
+var backends = []testBackend{
+	{
+		name: BackendMemory,
+		open: func(t *testing.T) (app.TransactionStore, func()) {
+			return CreateMemoryStore(testRetention, testAbandoned), func() {}
+		},
+		prune: func(ts app.TransactionStore, now time.Time) {
+			ts.(*MemoryStore).prune(now)
+		},
+	},
+	{
+		name: BackendFile,
+		open: func(t *testing.T) (app.TransactionStore, func()) {
+
+			dir, err := ioutil.TempDir("", "store")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			fs := CreateFileStore(filepath.Join(dir, "transactions.db"), testRetention, testAbandoned)
+			if err := fs.Open(); err != nil {
+				os.RemoveAll(dir)
+				t.Fatal(err)
+			}
+
+			return fs, func() {
+				fs.Close()
+				os.RemoveAll(dir)
+			}
+		},
+		prune: func(ts app.TransactionStore, now time.Time) {
+			ts.(*FileStore).prune(now)
+		},
+	},
+}
+
+func TestCreate(t *testing.T) {
+
+	for _, backend := range backends {
+		ts, closeStore := backend.open(t)
+
+		err := ts.Create(&app.Transaction{TransactionID: "tx1", Mode: "normal"})
+		if err != nil {
+			t.Fatalf("%s: %v", backend.name, err)
+		}
+
+		tx, err := ts.Get("tx1")
+		if err != nil {
+			t.Fatalf("%s: %v", backend.name, err)
+		}
+
+		if tx.State != StateCreated || len(tx.Transitions) != 1 || tx.CreatedAt.IsZero() {
+			t.Errorf("%s: unexpected transaction %+v", backend.name, tx)
+		}
+
+		err = ts.Create(&app.Transaction{TransactionID: "tx1"})
+		if err != ErrExists {
+			t.Errorf("%s: expected ErrExists, got %v", backend.name, err)
+		}
+
+		closeStore()
+	}
+}
+
+func TestNotFound(t *testing.T) {
+
+	for _, backend := range backends {
+		ts, closeStore := backend.open(t)
+
+		if _, err := ts.Get("tx1"); err != ErrNotFound {
+			t.Errorf("%s: expected ErrNotFound from Get, got %v", backend.name, err)
+		}
+
+		if err := ts.Update("tx1", func(*app.Transaction) {}); err != ErrNotFound {
+			t.Errorf("%s: expected ErrNotFound from Update, got %v", backend.name, err)
+		}
+
+		closeStore()
+	}
+}
+
+func TestGetReturnsCopy(t *testing.T) {
+
+	for _, backend := range backends {
+		ts, closeStore := backend.open(t)
+
+		ts.Create(&app.Transaction{TransactionID: "tx1"})
+
+		tx, _ := ts.Get("tx1")
+		tx.State = StateFailed
+		tx.Transitions = append(tx.Transitions, app.Transition{State: StateFailed})
+
+		list, _ := ts.List()
+		list[0].State = StateResolved
+
+		tx, _ = ts.Get("tx1")
+		if tx.State != StateCreated || len(tx.Transitions) != 1 {
+			t.Errorf("%s: stored transaction was modified by caller", backend.name)
+		}
+
+		closeStore()
+	}
+}
+
+func TestUpdateIsAtomic(t *testing.T) {
+
+	for _, backend := range backends {
+		ts, closeStore := backend.open(t)
+
+		ts.Create(&app.Transaction{TransactionID: "tx1"})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ts.Update("tx1", func(tx *app.Transaction) {
+					ApplyTransition(tx, StateAssigned, "")
+				})
+			}()
+		}
+
+		wg.Wait()
+
+		tx, _ := ts.Get("tx1")
+		if len(tx.Transitions) != 51 {
+			t.Errorf("%s: expected 51 transitions, got %d", backend.name, len(tx.Transitions))
+		}
+
+		closeStore()
+	}
+}
+
+func TestListInOrderOfCreation(t *testing.T) {
+
+	for _, backend := range backends {
+		ts, closeStore := backend.open(t)
+
+		now := time.Now()
+		for i, id := range []string{"c", "a", "b"} {
+			ts.Create(&app.Transaction{TransactionID: id, CreatedAt: now.Add(time.Duration(i) * time.Second)})
+		}
+
+		list, err := ts.List()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		order := ""
+		for _, tx := range list {
+			order += tx.TransactionID
+		}
+
+		if order != "cab" {
+			t.Errorf("%s: unexpected order %s", backend.name, order)
+		}
+
+		closeStore()
+	}
+}
+
+func TestPrune(t *testing.T) {
+
+	for _, backend := range backends {
+		ts, closeStore := backend.open(t)
+
+		for _, id := range []string{"finished", "failed", "resolved", "abandoned"} {
+			ts.Create(&app.Transaction{TransactionID: id})
+		}
+
+		ts.Update("finished", func(tx *app.Transaction) {
+			ApplyOutcome(tx, "Confirmed", "")
+		})
+
+		ts.Update("failed", func(tx *app.Transaction) {
+			ApplyTransition(tx, StateFailed, "Supervisor did not prepare transaction")
+		})
+
+		ts.Update("resolved", func(tx *app.Transaction) {
+			ApplyTransition(tx, StateResolved, "ops: stuck")
+		})
+
+		// Nothing is old enough yet
+		backend.prune(ts, time.Now().Add(testRetention/2))
+		if _, err := ts.Get("finished"); err != nil {
+			t.Errorf("%s: transaction was pruned before retention period", backend.name)
+		}
+
+		backend.prune(ts, time.Now().Add(3*testRetention))
+
+		for _, id := range []string{"finished", "failed", "resolved"} {
+			if _, err := ts.Get(id); err != ErrNotFound {
+				t.Errorf("%s: %s transaction was not pruned", backend.name, id)
+			}
+		}
+
+		// Transaction might still be running
+		if _, err := ts.Get("abandoned"); err != nil {
+			t.Errorf("%s: unfinished transaction was pruned before abandoned period", backend.name)
+		}
+
+		backend.prune(ts, time.Now().Add(5*testRetention))
+
+		if _, err := ts.Get("abandoned"); err != ErrNotFound {
+			t.Errorf("%s: abandoned transaction was not pruned", backend.name)
+		}
+
+		closeStore()
+	}
+}
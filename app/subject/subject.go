@@ -0,0 +1,119 @@
+package subject
+
+import (
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const DefaultPrefix = "twist"
+
+var ErrInvalidTransactionID = status.Error(codes.InvalidArgument, "Invalid transaction ID")
+
+// Builder constructs every subject of signal bus, so environments and tenants
+// sharing one NATS cluster never see each other's signals. Layout is
+// <prefix>[.<tenant>].transaction.<transactionID>.<name>, or
+// <prefix>[.<tenant>].instance.<instanceID>.transaction.<transactionID>.<name>
+// for signals which only one commander instance is interested in
+type Builder struct {
+	namespace string
+}
+
+func CreateBuilder(prefix string, tenant string) (*Builder, error) {
+
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+
+	// Prefix might have multiple tokens, like "twist.staging"
+	for _, token := range strings.Split(prefix, ".") {
+		if !IsValidToken(token) {
+			return nil, errors.New("Invalid subject prefix: " + prefix)
+		}
+	}
+
+	namespace := prefix
+
+	if tenant != "" {
+		if !IsValidToken(tenant) {
+			return nil, errors.New("Invalid tenant: " + tenant)
+		}
+
+		namespace += "." + tenant
+	}
+
+	return &Builder{
+		namespace: namespace,
+	}, nil
+}
+
+// Namespace returns prefix and tenant which all subjects start with
+func (b *Builder) Namespace() string {
+	return b.namespace
+}
+
+// TransactionEvents is where runner emits events of transaction
+func (b *Builder) TransactionEvents(transactionID string) (string, error) {
+	return b.transaction(transactionID, "eventEmitted")
+}
+
+// TransactionCommands is where commands are published to runner
+func (b *Builder) TransactionCommands(transactionID string) (string, error) {
+	return b.transaction(transactionID, "cmdReceived")
+}
+
+// TransactionRequests is where commands are sent in request mode
+func (b *Builder) TransactionRequests(transactionID string) (string, error) {
+	return b.transaction(transactionID, "cmdRequest")
+}
+
+// InstanceEvents is where runner emits events caused by commands of a single
+// commander instance, so other instances never receive them
+func (b *Builder) InstanceEvents(instanceID string, transactionID string) (string, error) {
+
+	if !IsValidToken(instanceID) {
+		return "", errors.New("Invalid instance ID: " + instanceID)
+	}
+
+	if !IsValidToken(transactionID) {
+		return "", ErrInvalidTransactionID
+	}
+
+	return b.namespace + ".instance." + instanceID + ".transaction." + transactionID + ".eventEmitted", nil
+}
+
+// Supervisor returns subject of supervisor method
+func (b *Builder) Supervisor(method string) string {
+	return b.namespace + ".supervisor." + method
+}
+
+func (b *Builder) transaction(transactionID string, name string) (string, error) {
+
+	if !IsValidToken(transactionID) {
+		return "", ErrInvalidTransactionID
+	}
+
+	return b.namespace + ".transaction." + transactionID + "." + name, nil
+}
+
+// IsValidToken reports whether s can be used as a single subject token. Dots
+// and wildcards would let caller address subjects of someone else.
+func IsValidToken(s string) bool {
+
+	if s == "" {
+		return false
+	}
+
+	for _, c := range s {
+		switch {
+		case c == '.', c == '*', c == '>':
+			return false
+		case c <= ' ', c == 0x7f:
+			return false
+		}
+	}
+
+	return true
+}
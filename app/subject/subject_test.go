@@ -0,0 +1,112 @@
+package subject
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIsValidToken(t *testing.T) {
+
+	cases := []struct {
+		token string
+		valid bool
+	}{
+		{"0ea1befc-cbab-11f1-81f8-dab39415e11d", true},
+		{"tx_1", true},
+		{"", false},
+		{"a.b", false},
+		{".", false},
+		{"*", false},
+		{"tx*", false},
+		{">", false},
+		{"tx>", false},
+		{"a b", false},
+		{" ", false},
+		{"tx\t", false},
+		{"tx\n", false},
+		{"tx\x7f", false},
+	}
+
+	for _, c := range cases {
+		if IsValidToken(c.token) != c.valid {
+			t.Errorf("token %q: expected valid %v", c.token, c.valid)
+		}
+	}
+}
+
+func TestCreateBuilder(t *testing.T) {
+
+	cases := []struct {
+		prefix    string
+		tenant    string
+		namespace string
+		valid     bool
+	}{
+		{"", "", DefaultPrefix, true},
+		{"twist.staging", "", "twist.staging", true},
+		{"twist", "acme", "twist.acme", true},
+		{"twist.", "", "", false},
+		{"twist.*", "", "", false},
+		{"twist", "a.b", "", false},
+		{"twist", ">", "", false},
+		{"twist", "a b", "", false},
+	}
+
+	for _, c := range cases {
+		b, err := CreateBuilder(c.prefix, c.tenant)
+		if (err == nil) != c.valid {
+			t.Errorf("prefix %q, tenant %q: unexpected result %v", c.prefix, c.tenant, err)
+			continue
+		}
+
+		if err == nil && b.Namespace() != c.namespace {
+			t.Errorf("prefix %q, tenant %q: expected namespace %s, got %s", c.prefix, c.tenant, c.namespace, b.Namespace())
+		}
+	}
+}
+
+func TestBuilderSubjects(t *testing.T) {
+
+	b, _ := CreateBuilder("twist", "acme")
+
+	subject, err := b.TransactionEvents("tx1")
+	if err != nil || subject != "twist.acme.transaction.tx1.eventEmitted" {
+		t.Errorf("unexpected events subject %s, %v", subject, err)
+	}
+
+	subject, err = b.TransactionCommands("tx1")
+	if err != nil || subject != "twist.acme.transaction.tx1.cmdReceived" {
+		t.Errorf("unexpected commands subject %s, %v", subject, err)
+	}
+
+	subject, err = b.TransactionRequests("tx1")
+	if err != nil || subject != "twist.acme.transaction.tx1.cmdRequest" {
+		t.Errorf("unexpected requests subject %s, %v", subject, err)
+	}
+
+	subject, err = b.InstanceEvents("i1", "tx1")
+	if err != nil || subject != "twist.acme.instance.i1.transaction.tx1.eventEmitted" {
+		t.Errorf("unexpected instance subject %s, %v", subject, err)
+	}
+
+	if subject := b.Supervisor("prepare"); subject != "twist.acme.supervisor.prepare" {
+		t.Errorf("unexpected supervisor subject %s", subject)
+	}
+
+	// Transaction ID must not address subjects of other transactions
+	for _, id := range []string{"", ".", "*", ">", "a.b", "a b"} {
+		if _, err := b.TransactionEvents(id); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("transaction ID %q: expected invalid argument, got %v", id, err)
+		}
+
+		if _, err := b.InstanceEvents("i1", id); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("transaction ID %q: expected invalid argument for instance subject, got %v", id, err)
+		}
+	}
+
+	if _, err := b.InstanceEvents("i.1", "tx1"); err == nil {
+		t.Error("expected invalid instance ID to be rejected")
+	}
+}
@@ -0,0 +1,40 @@
+package supervisor
+
+import (
+	"golang.org/x/net/context"
+
+	"twist-commander/app/breaker"
+	app "twist-commander/app/interface"
+	pb "twist-commander/pb"
+)
+
+// BreakerClient stops calling supervisor while it is degraded
+type BreakerClient struct {
+	app.SupervisorClient
+	breaker *breaker.Breaker
+}
+
+func CreateBreakerClient(client app.SupervisorClient, b *breaker.Breaker) *BreakerClient {
+	return &BreakerClient{
+		SupervisorClient: client,
+		breaker:          b,
+	}
+}
+
+func (s *BreakerClient) PrepareTransaction(ctx context.Context, in *pb.PrepareTransactionRequest) (*pb.PrepareTransactionReply, error) {
+
+	err := s.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+
+	// Rejected or unsuccessful request still means that supervisor is alive
+	reply, err := s.SupervisorClient.PrepareTransaction(ctx, in)
+	if breaker.IsFailure(err) {
+		s.breaker.Failure()
+	} else {
+		s.breaker.Success()
+	}
+
+	return reply, err
+}
@@ -0,0 +1,147 @@
+package supervisor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	_ "google.golang.org/grpc/health"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+	"google.golang.org/grpc/status"
+
+	pb "twist-commander/pb"
+)
+
+type GRPCClient struct {
+	hosts       []string
+	balancer    string
+	healthCheck bool
+	timeout     int64
+	resolver    *manual.Resolver
+	conn        *grpc.ClientConn
+	client      pb.SupervisorClient
+}
+
+func CreateGRPCClient(hosts []string, balancer string, healthCheck bool, timeout time.Duration) *GRPCClient {
+
+	if balancer == "" {
+		balancer = "round_robin"
+	}
+
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	return &GRPCClient{
+		hosts:       hosts,
+		balancer:    balancer,
+		healthCheck: healthCheck,
+		timeout:     int64(timeout),
+	}
+}
+
+func createAddresses(hosts []string) []resolver.Address {
+
+	addrs := make([]resolver.Address, 0, len(hosts))
+	for _, host := range hosts {
+		addrs = append(addrs, resolver.Address{Addr: host})
+	}
+
+	return addrs
+}
+
+func (s *GRPCClient) Connect() error {
+
+	log.WithFields(log.Fields{
+		"hosts":    strings.Join(s.hosts, ","),
+		"balancer": s.balancer,
+	}).Info("Connecting to supervisor")
+
+	if len(s.hosts) == 0 {
+		return errors.New("No supervisor host was configured")
+	}
+
+	// All supervisor endpoints are resolved from configuration
+	r := manual.NewBuilderWithScheme("supervisor")
+	r.InitialState(resolver.State{Addresses: createAddresses(s.hosts)})
+	s.resolver = r
+
+	serviceConfig := fmt.Sprintf(`{"loadBalancingPolicy":"%s"}`, s.balancer)
+	if s.healthCheck {
+		serviceConfig = fmt.Sprintf(`{"loadBalancingPolicy":"%s","healthCheckConfig":{"serviceName":""}}`, s.balancer)
+	}
+
+	// Connection is established in background, so supervisor is not required
+	// to be available at startup
+	conn, err := grpc.Dial(
+		r.Scheme()+":///supervisor",
+		grpc.WithInsecure(),
+		grpc.WithResolvers(r),
+		grpc.WithDefaultServiceConfig(serviceConfig),
+	)
+	if err != nil {
+		return err
+	}
+
+	s.conn = conn
+	s.client = pb.NewSupervisorClient(conn)
+
+	return nil
+}
+
+func (s *GRPCClient) Close() {
+	s.conn.Close()
+}
+
+// IsHealthy returns true once connection to some supervisor is ready,
+// supervisors which were never reached are not trusted
+func (s *GRPCClient) IsHealthy() bool {
+	return s.conn.GetState() == connectivity.Ready
+}
+
+func (s *GRPCClient) PrepareTransaction(ctx context.Context, in *pb.PrepareTransactionRequest) (*pb.PrepareTransactionReply, error) {
+
+	// Fail fast instead of waiting for supervisor which is down
+	if !s.IsHealthy() {
+		return nil, status.Error(codes.Unavailable, "No healthy supervisor is available")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(atomic.LoadInt64(&s.timeout)))
+	defer cancel()
+
+	return s.client.PrepareTransaction(ctx, in)
+}
+
+// Reconfigure applies new supervisor endpoints without dropping connections
+// which are still in the list
+func (s *GRPCClient) Reconfigure(hosts []string, timeout time.Duration) error {
+
+	if len(hosts) == 0 {
+		return errors.New("No supervisor host was configured")
+	}
+
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	atomic.StoreInt64(&s.timeout, int64(timeout))
+
+	if s.resolver != nil {
+		s.resolver.UpdateState(resolver.State{Addresses: createAddresses(hosts)})
+	}
+
+	log.WithFields(log.Fields{
+		"hosts":   strings.Join(hosts, ","),
+		"timeout": timeout,
+	}).Info("Updated supervisor settings")
+
+	return nil
+}
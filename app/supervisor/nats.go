@@ -0,0 +1,101 @@
+package supervisor
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"twist-commander/app/signalbus"
+	pb "twist-commander/pb"
+)
+
+// NATSClient talks to supervisor with request/reply over signal bus, for sites
+// which expose NATS only.
+type NATSClient struct {
+	signalbus signalbus.Bus
+	subject   string
+	timeout   int64
+}
+
+func CreateNATSClient(sb signalbus.Bus, subject string, timeout time.Duration) *NATSClient {
+
+	if subject == "" {
+		subject = "twist.supervisor.prepareTransaction"
+	}
+
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	return &NATSClient{
+		signalbus: sb,
+		subject:   subject,
+		timeout:   int64(timeout),
+	}
+}
+
+func (s *NATSClient) Connect() error {
+
+	log.WithFields(log.Fields{
+		"subject": s.subject,
+	}).Info("Connecting to supervisor via signal server")
+
+	return nil
+}
+
+func (s *NATSClient) Close() {
+}
+
+func (s *NATSClient) IsHealthy() bool {
+	return s.signalbus.IsAvailable()
+}
+
+func (s *NATSClient) PrepareTransaction(ctx context.Context, in *pb.PrepareTransactionRequest) (*pb.PrepareTransactionReply, error) {
+
+	if !s.IsHealthy() {
+		return nil, status.Error(codes.Unavailable, "Signal server is not connected")
+	}
+
+	data, err := proto.Marshal(in)
+	if err != nil {
+		return nil, errors.New("Failed to create request")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(atomic.LoadInt64(&s.timeout)))
+	defer cancel()
+
+	res, err := s.signalbus.Request(ctx, s.subject, data)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, status.Error(codes.DeadlineExceeded, "Supervisor did not reply in time")
+		}
+
+		return nil, status.Error(codes.Unavailable, "Failed to send request to supervisor")
+	}
+
+	var reply pb.PrepareTransactionReply
+	err = proto.Unmarshal(res, &reply)
+	if err != nil {
+		return nil, errors.New("Failed to parse reply from supervisor")
+	}
+
+	return &reply, nil
+}
+
+// Reconfigure applies new timeout, hosts are not used by this transport
+func (s *NATSClient) Reconfigure(hosts []string, timeout time.Duration) error {
+
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	atomic.StoreInt64(&s.timeout, int64(timeout))
+
+	return nil
+}
@@ -0,0 +1,7 @@
+package supervisor
+
+import (
+	"time"
+)
+
+const DefaultTimeout = time.Second
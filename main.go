@@ -1,7 +1,10 @@
 package main
 
 import (
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
@@ -38,9 +41,33 @@ func main() {
 	}
 
 	// Starting application
-	err = a.Run()
-	if err != nil {
-		log.Fatal(err)
-		return
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- a.Run()
+	}()
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+
+	for {
+		select {
+		case err := <-errCh:
+			if err != nil {
+				log.Fatal(err)
+			}
+			return
+		case s := <-sig:
+
+			// Reload configuration
+			if s == syscall.SIGHUP {
+				log.Info("Reloading configuration")
+				a.ReloadConfig()
+				continue
+			}
+
+			log.Info("Received signal: ", s)
+			a.Uninit()
+			return
+		}
 	}
 }
@@ -159,11 +159,12 @@ func (m *RegisterTasksRequest) GetTasks() []*TransactionTask {
 }
 
 type RegisterTasksReply struct {
-	Success              bool     `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	TransactionID        string   `protobuf:"bytes,2,opt,name=transactionID,proto3" json:"transactionID,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Success              bool             `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	TransactionID        string           `protobuf:"bytes,2,opt,name=transactionID,proto3" json:"transactionID,omitempty"`
+	Violations           []*TaskViolation `protobuf:"bytes,3,rep,name=violations,proto3" json:"violations,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
 }
 
 func (m *RegisterTasksReply) Reset()         { *m = RegisterTasksReply{} }
@@ -205,6 +206,13 @@ func (m *RegisterTasksReply) GetTransactionID() string {
 	return ""
 }
 
+func (m *RegisterTasksReply) GetViolations() []*TaskViolation {
+	if m != nil {
+		return m.Violations
+	}
+	return nil
+}
+
 type ConfirmTransactionRequest struct {
 	TransactionID        string               `protobuf:"bytes,1,opt,name=transactionID,proto3" json:"transactionID,omitempty"`
 	Tasks                []*TransactionTask   `protobuf:"bytes,2,rep,name=tasks,proto3" json:"tasks,omitempty"`
@@ -261,11 +269,15 @@ func (m *ConfirmTransactionRequest) GetExpires() *timestamp.Timestamp {
 }
 
 type ConfirmTransactionReply struct {
-	Success              bool     `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	TransactionID        string   `protobuf:"bytes,2,opt,name=transactionID,proto3" json:"transactionID,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Success              bool             `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	TransactionID        string           `protobuf:"bytes,2,opt,name=transactionID,proto3" json:"transactionID,omitempty"`
+	Violations           []*TaskViolation `protobuf:"bytes,3,rep,name=violations,proto3" json:"violations,omitempty"`
+	Transcript           []*SimulatedCall `protobuf:"bytes,4,rep,name=transcript,proto3" json:"transcript,omitempty"`
+	Outcome              string           `protobuf:"bytes,5,opt,name=outcome,proto3" json:"outcome,omitempty"`
+	OutcomeDetail        string           `protobuf:"bytes,6,opt,name=outcomeDetail,proto3" json:"outcomeDetail,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
 }
 
 func (m *ConfirmTransactionReply) Reset()         { *m = ConfirmTransactionReply{} }
@@ -307,9 +319,38 @@ func (m *ConfirmTransactionReply) GetTransactionID() string {
 	return ""
 }
 
+func (m *ConfirmTransactionReply) GetViolations() []*TaskViolation {
+	if m != nil {
+		return m.Violations
+	}
+	return nil
+}
+
+func (m *ConfirmTransactionReply) GetTranscript() []*SimulatedCall {
+	if m != nil {
+		return m.Transcript
+	}
+	return nil
+}
+
+func (m *ConfirmTransactionReply) GetOutcome() string {
+	if m != nil {
+		return m.Outcome
+	}
+	return ""
+}
+
+func (m *ConfirmTransactionReply) GetOutcomeDetail() string {
+	if m != nil {
+		return m.OutcomeDetail
+	}
+	return ""
+}
+
 type TransactionTask struct {
 	Confirm              *TransactionTaskAction `protobuf:"bytes,1,opt,name=confirm,proto3" json:"confirm,omitempty"`
 	Cancel               *TransactionTaskAction `protobuf:"bytes,2,opt,name=cancel,proto3" json:"cancel,omitempty"`
+	Id                   string                 `protobuf:"bytes,3,opt,name=id,proto3" json:"id,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}               `json:"-"`
 	XXX_unrecognized     []byte                 `json:"-"`
 	XXX_sizecache        int32                  `json:"-"`
@@ -354,6 +395,52 @@ func (m *TransactionTask) GetCancel() *TransactionTaskAction {
 	return nil
 }
 
+func (m *TransactionTask) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type TransactionTaskList struct {
+	Tasks                []*TransactionTask `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *TransactionTaskList) Reset()         { *m = TransactionTaskList{} }
+func (m *TransactionTaskList) String() string { return proto.CompactTextString(m) }
+func (*TransactionTaskList) ProtoMessage()    {}
+func (*TransactionTaskList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_36bf467611423882, []int{7}
+}
+
+func (m *TransactionTaskList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TransactionTaskList.Unmarshal(m, b)
+}
+func (m *TransactionTaskList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TransactionTaskList.Marshal(b, m, deterministic)
+}
+func (m *TransactionTaskList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TransactionTaskList.Merge(m, src)
+}
+func (m *TransactionTaskList) XXX_Size() int {
+	return xxx_messageInfo_TransactionTaskList.Size(m)
+}
+func (m *TransactionTaskList) XXX_DiscardUnknown() {
+	xxx_messageInfo_TransactionTaskList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TransactionTaskList proto.InternalMessageInfo
+
+func (m *TransactionTaskList) GetTasks() []*TransactionTask {
+	if m != nil {
+		return m.Tasks
+	}
+	return nil
+}
+
 type TransactionTaskAction struct {
 	Type                 string            `protobuf:"bytes,1,opt,name=Type,proto3" json:"Type,omitempty"`
 	Method               string            `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"`
@@ -369,7 +456,7 @@ func (m *TransactionTaskAction) Reset()         { *m = TransactionTaskAction{} }
 func (m *TransactionTaskAction) String() string { return proto.CompactTextString(m) }
 func (*TransactionTaskAction) ProtoMessage()    {}
 func (*TransactionTaskAction) Descriptor() ([]byte, []int) {
-	return fileDescriptor_36bf467611423882, []int{7}
+	return fileDescriptor_36bf467611423882, []int{8}
 }
 
 func (m *TransactionTaskAction) XXX_Unmarshal(b []byte) error {
@@ -436,7 +523,7 @@ func (m *CancelTransactionRequest) Reset()         { *m = CancelTransactionReque
 func (m *CancelTransactionRequest) String() string { return proto.CompactTextString(m) }
 func (*CancelTransactionRequest) ProtoMessage()    {}
 func (*CancelTransactionRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_36bf467611423882, []int{8}
+	return fileDescriptor_36bf467611423882, []int{9}
 }
 
 func (m *CancelTransactionRequest) XXX_Unmarshal(b []byte) error {
@@ -465,18 +552,21 @@ func (m *CancelTransactionRequest) GetTransactionID() string {
 }
 
 type CancelTransactionReply struct {
-	Success              bool     `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	TransactionID        string   `protobuf:"bytes,2,opt,name=transactionID,proto3" json:"transactionID,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Success              bool             `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	TransactionID        string           `protobuf:"bytes,2,opt,name=transactionID,proto3" json:"transactionID,omitempty"`
+	Transcript           []*SimulatedCall `protobuf:"bytes,3,rep,name=transcript,proto3" json:"transcript,omitempty"`
+	Outcome              string           `protobuf:"bytes,4,opt,name=outcome,proto3" json:"outcome,omitempty"`
+	OutcomeDetail        string           `protobuf:"bytes,5,opt,name=outcomeDetail,proto3" json:"outcomeDetail,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
 }
 
 func (m *CancelTransactionReply) Reset()         { *m = CancelTransactionReply{} }
 func (m *CancelTransactionReply) String() string { return proto.CompactTextString(m) }
 func (*CancelTransactionReply) ProtoMessage()    {}
 func (*CancelTransactionReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_36bf467611423882, []int{9}
+	return fileDescriptor_36bf467611423882, []int{10}
 }
 
 func (m *CancelTransactionReply) XXX_Unmarshal(b []byte) error {
@@ -511,193 +601,962 @@ func (m *CancelTransactionReply) GetTransactionID() string {
 	return ""
 }
 
-func init() {
-	proto.RegisterType((*CreateTransactionRequest)(nil), "twist.CreateTransactionRequest")
-	proto.RegisterType((*CreateTransactionReply)(nil), "twist.CreateTransactionReply")
-	proto.RegisterType((*RegisterTasksRequest)(nil), "twist.RegisterTasksRequest")
-	proto.RegisterType((*RegisterTasksReply)(nil), "twist.RegisterTasksReply")
-	proto.RegisterType((*ConfirmTransactionRequest)(nil), "twist.ConfirmTransactionRequest")
-	proto.RegisterType((*ConfirmTransactionReply)(nil), "twist.ConfirmTransactionReply")
-	proto.RegisterType((*TransactionTask)(nil), "twist.TransactionTask")
-	proto.RegisterType((*TransactionTaskAction)(nil), "twist.TransactionTaskAction")
-	proto.RegisterMapType((map[string]string)(nil), "twist.TransactionTaskAction.HeadersEntry")
-	proto.RegisterType((*CancelTransactionRequest)(nil), "twist.CancelTransactionRequest")
-	proto.RegisterType((*CancelTransactionReply)(nil), "twist.CancelTransactionReply")
+func (m *CancelTransactionReply) GetTranscript() []*SimulatedCall {
+	if m != nil {
+		return m.Transcript
+	}
+	return nil
 }
 
-func init() { proto.RegisterFile("commander.proto", fileDescriptor_36bf467611423882) }
+func (m *CancelTransactionReply) GetOutcome() string {
+	if m != nil {
+		return m.Outcome
+	}
+	return ""
+}
 
-var fileDescriptor_36bf467611423882 = []byte{
-	// 521 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x54, 0xcb, 0x6e, 0xd3, 0x40,
-	0x14, 0xad, 0x93, 0x26, 0x21, 0x37, 0x54, 0x85, 0xab, 0x12, 0x5c, 0xf3, 0x68, 0x64, 0xb1, 0x08,
-	0x12, 0x72, 0xa5, 0x50, 0x21, 0xd4, 0x15, 0x28, 0x20, 0xd1, 0xad, 0x15, 0xa4, 0xb2, 0x9c, 0xda,
-	0xb7, 0xa9, 0xa9, 0xed, 0x31, 0x33, 0x63, 0xc0, 0x2b, 0xbe, 0x86, 0xdf, 0xe2, 0x1b, 0xf8, 0x04,
-	0xe4, 0xb1, 0x87, 0xb4, 0x89, 0x53, 0x24, 0x94, 0xee, 0x66, 0xec, 0x73, 0xee, 0x9c, 0x73, 0x5f,
-	0xb0, 0x1b, 0xf0, 0x24, 0x61, 0x69, 0x48, 0xc2, 0xcb, 0x04, 0x57, 0x1c, 0x3b, 0xea, 0x5b, 0x24,
-	0x95, 0x73, 0x30, 0xe7, 0x7c, 0x1e, 0xd3, 0xa1, 0xfe, 0x78, 0x96, 0x9f, 0x1f, 0xaa, 0x28, 0x21,
-	0xa9, 0x58, 0x92, 0x55, 0x38, 0xd7, 0x03, 0x7b, 0x2a, 0x88, 0x29, 0x9a, 0x09, 0x96, 0x4a, 0x16,
-	0xa8, 0x88, 0xa7, 0x3e, 0x7d, 0xc9, 0x49, 0x2a, 0x44, 0xd8, 0x4e, 0x78, 0x48, 0xb6, 0x35, 0xb2,
-	0xc6, 0x7d, 0x5f, 0x9f, 0xdd, 0x53, 0x18, 0x36, 0xe0, 0xb3, 0xb8, 0x40, 0x1b, 0x7a, 0x32, 0x0f,
-	0x02, 0x92, 0x52, 0x13, 0xee, 0xf8, 0xe6, 0x8a, 0xcf, 0x60, 0x47, 0x2d, 0xd0, 0x27, 0xef, 0xec,
-	0x96, 0x0e, 0x78, 0xfd, 0xa3, 0xfb, 0x19, 0xf6, 0x7c, 0x9a, 0x47, 0x52, 0x91, 0x98, 0x31, 0x79,
-	0x29, 0x8d, 0x8a, 0x15, 0xb6, 0xd5, 0xc0, 0xc6, 0x17, 0xd0, 0x51, 0x25, 0xcb, 0x6e, 0x8d, 0xda,
-	0xe3, 0xc1, 0x64, 0xe8, 0x69, 0xff, 0xde, 0x15, 0x95, 0x65, 0x50, 0xbf, 0x02, 0xb9, 0x33, 0xc0,
-	0xa5, 0xb7, 0x36, 0xe1, 0xe0, 0xa7, 0x05, 0xfb, 0x53, 0x9e, 0x9e, 0x47, 0x22, 0x69, 0xc8, 0xe6,
-	0x2d, 0xf8, 0xc0, 0x23, 0xe8, 0xd1, 0xf7, 0x2c, 0x12, 0x24, 0xed, 0xf6, 0xc8, 0x1a, 0x0f, 0x26,
-	0x8e, 0x57, 0x15, 0xdc, 0x33, 0x05, 0xf7, 0x66, 0xa6, 0xe0, 0xbe, 0x81, 0xba, 0x9f, 0xe0, 0x61,
-	0x93, 0xcc, 0x4d, 0xa4, 0xe0, 0x07, 0xec, 0x2e, 0x49, 0xc5, 0x57, 0xd0, 0x0b, 0xaa, 0xd7, 0x74,
-	0xc8, 0xc1, 0xe4, 0x71, 0xb3, 0xa7, 0xb7, 0x95, 0x0c, 0x03, 0xc6, 0x23, 0xe8, 0x06, 0x2c, 0x0d,
-	0x28, 0xd6, 0x2f, 0xfd, 0x8b, 0x56, 0x63, 0xdd, 0xdf, 0x16, 0x3c, 0x68, 0x44, 0x94, 0xdd, 0x3c,
-	0x2b, 0xb2, 0xbf, 0xdd, 0x5c, 0x9e, 0x71, 0x08, 0xdd, 0x84, 0xd4, 0x05, 0x0f, 0x6b, 0x37, 0xf5,
-	0x0d, 0xef, 0x41, 0x3b, 0x17, 0x91, 0xce, 0x69, 0xdf, 0x2f, 0x8f, 0x38, 0x85, 0xde, 0x05, 0xb1,
-	0x90, 0x84, 0xb4, 0xb7, 0x75, 0x65, 0x9e, 0xdf, 0x24, 0xc7, 0xfb, 0x50, 0x61, 0xdf, 0xa7, 0x4a,
-	0x14, 0xbe, 0x61, 0x96, 0xd9, 0xcd, 0x58, 0x11, 0x73, 0x16, 0xda, 0x1d, 0x1d, 0xda, 0x5c, 0x9d,
-	0x63, 0xb8, 0x7b, 0x95, 0x52, 0x0a, 0xb8, 0xa4, 0xa2, 0xd6, 0x5a, 0x1e, 0x71, 0x0f, 0x3a, 0x5f,
-	0x59, 0x9c, 0x53, 0xad, 0xb4, 0xba, 0x1c, 0xb7, 0x5e, 0x5b, 0xee, 0x1b, 0xb0, 0xa7, 0xda, 0xfc,
-	0xff, 0x36, 0x9d, 0x1e, 0xea, 0xd5, 0x08, 0x1b, 0xe8, 0x87, 0xc9, 0xaf, 0x16, 0xf4, 0xa7, 0x66,
-	0x35, 0xe1, 0x47, 0xb8, 0xbf, 0xb2, 0x3c, 0xf0, 0xa0, 0x4e, 0xe4, 0xba, 0x35, 0xe4, 0x3c, 0x59,
-	0x0f, 0xc8, 0xe2, 0xc2, 0xdd, 0xc2, 0x13, 0xd8, 0xb9, 0x36, 0xcd, 0xf8, 0xa8, 0x66, 0x34, 0xed,
-	0x13, 0x67, 0xbf, 0xf9, 0x67, 0x15, 0xea, 0x14, 0x70, 0x75, 0x34, 0x70, 0x64, 0x14, 0xac, 0x1b,
-	0x6e, 0xe7, 0xe9, 0x0d, 0x88, 0x2a, 0x72, 0xe9, 0x7d, 0x39, 0xc7, 0x0b, 0xef, 0x6b, 0xea, 0xb7,
-	0xf0, 0xde, 0x58, 0x1e, 0x77, 0xeb, 0xac, 0xab, 0x07, 0xfd, 0xe5, 0x9f, 0x00, 0x00, 0x00, 0xff,
-	0xff, 0x03, 0x94, 0x36, 0x96, 0x01, 0x06, 0x00, 0x00,
+func (m *CancelTransactionReply) GetOutcomeDetail() string {
+	if m != nil {
+		return m.OutcomeDetail
+	}
+	return ""
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+type SimulatedCall struct {
+	Sequence             int32             `protobuf:"varint,1,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	TaskID               string            `protobuf:"bytes,2,opt,name=taskID,proto3" json:"taskID,omitempty"`
+	Action               string            `protobuf:"bytes,3,opt,name=action,proto3" json:"action,omitempty"`
+	Method               string            `protobuf:"bytes,4,opt,name=method,proto3" json:"method,omitempty"`
+	Uri                  string            `protobuf:"bytes,5,opt,name=uri,proto3" json:"uri,omitempty"`
+	Headers              map[string]string `protobuf:"bytes,6,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Payload              string            `protobuf:"bytes,7,opt,name=payload,proto3" json:"payload,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+func (m *SimulatedCall) Reset()         { *m = SimulatedCall{} }
+func (m *SimulatedCall) String() string { return proto.CompactTextString(m) }
+func (*SimulatedCall) ProtoMessage()    {}
+func (*SimulatedCall) Descriptor() ([]byte, []int) {
+	return fileDescriptor_36bf467611423882, []int{11}
+}
 
-// CommanderClient is the client API for Commander service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type CommanderClient interface {
-	CreateTransaction(ctx context.Context, in *CreateTransactionRequest, opts ...grpc.CallOption) (*CreateTransactionReply, error)
-	RegisterTasks(ctx context.Context, in *RegisterTasksRequest, opts ...grpc.CallOption) (*RegisterTasksReply, error)
-	ConfirmTransaction(ctx context.Context, in *ConfirmTransactionRequest, opts ...grpc.CallOption) (*ConfirmTransactionReply, error)
-	CancelTransaction(ctx context.Context, in *CancelTransactionRequest, opts ...grpc.CallOption) (*CancelTransactionReply, error)
+func (m *SimulatedCall) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SimulatedCall.Unmarshal(m, b)
+}
+func (m *SimulatedCall) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SimulatedCall.Marshal(b, m, deterministic)
+}
+func (m *SimulatedCall) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SimulatedCall.Merge(m, src)
+}
+func (m *SimulatedCall) XXX_Size() int {
+	return xxx_messageInfo_SimulatedCall.Size(m)
+}
+func (m *SimulatedCall) XXX_DiscardUnknown() {
+	xxx_messageInfo_SimulatedCall.DiscardUnknown(m)
 }
 
-type commanderClient struct {
-	cc *grpc.ClientConn
+var xxx_messageInfo_SimulatedCall proto.InternalMessageInfo
+
+func (m *SimulatedCall) GetSequence() int32 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
 }
 
-func NewCommanderClient(cc *grpc.ClientConn) CommanderClient {
-	return &commanderClient{cc}
+func (m *SimulatedCall) GetTaskID() string {
+	if m != nil {
+		return m.TaskID
+	}
+	return ""
 }
 
-func (c *commanderClient) CreateTransaction(ctx context.Context, in *CreateTransactionRequest, opts ...grpc.CallOption) (*CreateTransactionReply, error) {
-	out := new(CreateTransactionReply)
-	err := c.cc.Invoke(ctx, "/twist.Commander/CreateTransaction", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *SimulatedCall) GetAction() string {
+	if m != nil {
+		return m.Action
 	}
-	return out, nil
+	return ""
 }
 
-func (c *commanderClient) RegisterTasks(ctx context.Context, in *RegisterTasksRequest, opts ...grpc.CallOption) (*RegisterTasksReply, error) {
-	out := new(RegisterTasksReply)
-	err := c.cc.Invoke(ctx, "/twist.Commander/RegisterTasks", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *SimulatedCall) GetMethod() string {
+	if m != nil {
+		return m.Method
 	}
-	return out, nil
+	return ""
 }
 
-func (c *commanderClient) ConfirmTransaction(ctx context.Context, in *ConfirmTransactionRequest, opts ...grpc.CallOption) (*ConfirmTransactionReply, error) {
-	out := new(ConfirmTransactionReply)
-	err := c.cc.Invoke(ctx, "/twist.Commander/ConfirmTransaction", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *SimulatedCall) GetUri() string {
+	if m != nil {
+		return m.Uri
 	}
-	return out, nil
+	return ""
 }
 
-func (c *commanderClient) CancelTransaction(ctx context.Context, in *CancelTransactionRequest, opts ...grpc.CallOption) (*CancelTransactionReply, error) {
-	out := new(CancelTransactionReply)
-	err := c.cc.Invoke(ctx, "/twist.Commander/CancelTransaction", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *SimulatedCall) GetHeaders() map[string]string {
+	if m != nil {
+		return m.Headers
 	}
-	return out, nil
+	return nil
 }
 
-// CommanderServer is the server API for Commander service.
-type CommanderServer interface {
-	CreateTransaction(context.Context, *CreateTransactionRequest) (*CreateTransactionReply, error)
-	RegisterTasks(context.Context, *RegisterTasksRequest) (*RegisterTasksReply, error)
-	ConfirmTransaction(context.Context, *ConfirmTransactionRequest) (*ConfirmTransactionReply, error)
-	CancelTransaction(context.Context, *CancelTransactionRequest) (*CancelTransactionReply, error)
+func (m *SimulatedCall) GetPayload() string {
+	if m != nil {
+		return m.Payload
+	}
+	return ""
 }
 
-// UnimplementedCommanderServer can be embedded to have forward compatible implementations.
-type UnimplementedCommanderServer struct {
+type ListTasksRequest struct {
+	TransactionID        string   `protobuf:"bytes,1,opt,name=transactionID,proto3" json:"transactionID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (*UnimplementedCommanderServer) CreateTransaction(ctx context.Context, req *CreateTransactionRequest) (*CreateTransactionReply, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method CreateTransaction not implemented")
+func (m *ListTasksRequest) Reset()         { *m = ListTasksRequest{} }
+func (m *ListTasksRequest) String() string { return proto.CompactTextString(m) }
+func (*ListTasksRequest) ProtoMessage()    {}
+func (*ListTasksRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_36bf467611423882, []int{12}
 }
-func (*UnimplementedCommanderServer) RegisterTasks(ctx context.Context, req *RegisterTasksRequest) (*RegisterTasksReply, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method RegisterTasks not implemented")
+
+func (m *ListTasksRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListTasksRequest.Unmarshal(m, b)
 }
-func (*UnimplementedCommanderServer) ConfirmTransaction(ctx context.Context, req *ConfirmTransactionRequest) (*ConfirmTransactionReply, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method ConfirmTransaction not implemented")
+func (m *ListTasksRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListTasksRequest.Marshal(b, m, deterministic)
 }
-func (*UnimplementedCommanderServer) CancelTransaction(ctx context.Context, req *CancelTransactionRequest) (*CancelTransactionReply, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method CancelTransaction not implemented")
+func (m *ListTasksRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListTasksRequest.Merge(m, src)
 }
-
-func RegisterCommanderServer(s *grpc.Server, srv CommanderServer) {
-	s.RegisterService(&_Commander_serviceDesc, srv)
+func (m *ListTasksRequest) XXX_Size() int {
+	return xxx_messageInfo_ListTasksRequest.Size(m)
+}
+func (m *ListTasksRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListTasksRequest.DiscardUnknown(m)
 }
 
-func _Commander_CreateTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CreateTransactionRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(CommanderServer).CreateTransaction(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/twist.Commander/CreateTransaction",
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CommanderServer).CreateTransaction(ctx, req.(*CreateTransactionRequest))
+var xxx_messageInfo_ListTasksRequest proto.InternalMessageInfo
+
+func (m *ListTasksRequest) GetTransactionID() string {
+	if m != nil {
+		return m.TransactionID
 	}
-	return interceptor(ctx, in, info, handler)
+	return ""
 }
 
-func _Commander_RegisterTasks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(RegisterTasksRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(CommanderServer).RegisterTasks(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/twist.Commander/RegisterTasks",
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CommanderServer).RegisterTasks(ctx, req.(*RegisterTasksRequest))
+type ListTasksReply struct {
+	Success              bool               `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	TransactionID        string             `protobuf:"bytes,2,opt,name=transactionID,proto3" json:"transactionID,omitempty"`
+	Tasks                []*TransactionTask `protobuf:"bytes,3,rep,name=tasks,proto3" json:"tasks,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *ListTasksReply) Reset()         { *m = ListTasksReply{} }
+func (m *ListTasksReply) String() string { return proto.CompactTextString(m) }
+func (*ListTasksReply) ProtoMessage()    {}
+func (*ListTasksReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_36bf467611423882, []int{13}
+}
+
+func (m *ListTasksReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListTasksReply.Unmarshal(m, b)
+}
+func (m *ListTasksReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListTasksReply.Marshal(b, m, deterministic)
+}
+func (m *ListTasksReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListTasksReply.Merge(m, src)
+}
+func (m *ListTasksReply) XXX_Size() int {
+	return xxx_messageInfo_ListTasksReply.Size(m)
+}
+func (m *ListTasksReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListTasksReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListTasksReply proto.InternalMessageInfo
+
+func (m *ListTasksReply) GetSuccess() bool {
+	if m != nil {
+		return m.Success
 	}
-	return interceptor(ctx, in, info, handler)
+	return false
 }
 
-func _Commander_ConfirmTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ConfirmTransactionRequest)
-	if err := dec(in); err != nil {
-		return nil, err
+func (m *ListTasksReply) GetTransactionID() string {
+	if m != nil {
+		return m.TransactionID
 	}
-	if interceptor == nil {
-		return srv.(CommanderServer).ConfirmTransaction(ctx, in)
+	return ""
+}
+
+func (m *ListTasksReply) GetTasks() []*TransactionTask {
+	if m != nil {
+		return m.Tasks
+	}
+	return nil
+}
+
+type ReplaceTasksRequest struct {
+	TransactionID        string             `protobuf:"bytes,1,opt,name=transactionID,proto3" json:"transactionID,omitempty"`
+	Tasks                []*TransactionTask `protobuf:"bytes,2,rep,name=tasks,proto3" json:"tasks,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *ReplaceTasksRequest) Reset()         { *m = ReplaceTasksRequest{} }
+func (m *ReplaceTasksRequest) String() string { return proto.CompactTextString(m) }
+func (*ReplaceTasksRequest) ProtoMessage()    {}
+func (*ReplaceTasksRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_36bf467611423882, []int{14}
+}
+
+func (m *ReplaceTasksRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReplaceTasksRequest.Unmarshal(m, b)
+}
+func (m *ReplaceTasksRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReplaceTasksRequest.Marshal(b, m, deterministic)
+}
+func (m *ReplaceTasksRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReplaceTasksRequest.Merge(m, src)
+}
+func (m *ReplaceTasksRequest) XXX_Size() int {
+	return xxx_messageInfo_ReplaceTasksRequest.Size(m)
+}
+func (m *ReplaceTasksRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReplaceTasksRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReplaceTasksRequest proto.InternalMessageInfo
+
+func (m *ReplaceTasksRequest) GetTransactionID() string {
+	if m != nil {
+		return m.TransactionID
+	}
+	return ""
+}
+
+func (m *ReplaceTasksRequest) GetTasks() []*TransactionTask {
+	if m != nil {
+		return m.Tasks
+	}
+	return nil
+}
+
+type ReplaceTasksReply struct {
+	Success              bool             `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	TransactionID        string           `protobuf:"bytes,2,opt,name=transactionID,proto3" json:"transactionID,omitempty"`
+	Violations           []*TaskViolation `protobuf:"bytes,3,rep,name=violations,proto3" json:"violations,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *ReplaceTasksReply) Reset()         { *m = ReplaceTasksReply{} }
+func (m *ReplaceTasksReply) String() string { return proto.CompactTextString(m) }
+func (*ReplaceTasksReply) ProtoMessage()    {}
+func (*ReplaceTasksReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_36bf467611423882, []int{15}
+}
+
+func (m *ReplaceTasksReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReplaceTasksReply.Unmarshal(m, b)
+}
+func (m *ReplaceTasksReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReplaceTasksReply.Marshal(b, m, deterministic)
+}
+func (m *ReplaceTasksReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReplaceTasksReply.Merge(m, src)
+}
+func (m *ReplaceTasksReply) XXX_Size() int {
+	return xxx_messageInfo_ReplaceTasksReply.Size(m)
+}
+func (m *ReplaceTasksReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReplaceTasksReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReplaceTasksReply proto.InternalMessageInfo
+
+func (m *ReplaceTasksReply) GetSuccess() bool {
+	if m != nil {
+		return m.Success
+	}
+	return false
+}
+
+func (m *ReplaceTasksReply) GetTransactionID() string {
+	if m != nil {
+		return m.TransactionID
+	}
+	return ""
+}
+
+func (m *ReplaceTasksReply) GetViolations() []*TaskViolation {
+	if m != nil {
+		return m.Violations
+	}
+	return nil
+}
+
+type RemoveTaskRequest struct {
+	TransactionID        string   `protobuf:"bytes,1,opt,name=transactionID,proto3" json:"transactionID,omitempty"`
+	TaskID               string   `protobuf:"bytes,2,opt,name=taskID,proto3" json:"taskID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RemoveTaskRequest) Reset()         { *m = RemoveTaskRequest{} }
+func (m *RemoveTaskRequest) String() string { return proto.CompactTextString(m) }
+func (*RemoveTaskRequest) ProtoMessage()    {}
+func (*RemoveTaskRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_36bf467611423882, []int{16}
+}
+
+func (m *RemoveTaskRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RemoveTaskRequest.Unmarshal(m, b)
+}
+func (m *RemoveTaskRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RemoveTaskRequest.Marshal(b, m, deterministic)
+}
+func (m *RemoveTaskRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RemoveTaskRequest.Merge(m, src)
+}
+func (m *RemoveTaskRequest) XXX_Size() int {
+	return xxx_messageInfo_RemoveTaskRequest.Size(m)
+}
+func (m *RemoveTaskRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RemoveTaskRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RemoveTaskRequest proto.InternalMessageInfo
+
+func (m *RemoveTaskRequest) GetTransactionID() string {
+	if m != nil {
+		return m.TransactionID
+	}
+	return ""
+}
+
+func (m *RemoveTaskRequest) GetTaskID() string {
+	if m != nil {
+		return m.TaskID
+	}
+	return ""
+}
+
+type RemoveTaskReply struct {
+	Success              bool     `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	TransactionID        string   `protobuf:"bytes,2,opt,name=transactionID,proto3" json:"transactionID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RemoveTaskReply) Reset()         { *m = RemoveTaskReply{} }
+func (m *RemoveTaskReply) String() string { return proto.CompactTextString(m) }
+func (*RemoveTaskReply) ProtoMessage()    {}
+func (*RemoveTaskReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_36bf467611423882, []int{17}
+}
+
+func (m *RemoveTaskReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RemoveTaskReply.Unmarshal(m, b)
+}
+func (m *RemoveTaskReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RemoveTaskReply.Marshal(b, m, deterministic)
+}
+func (m *RemoveTaskReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RemoveTaskReply.Merge(m, src)
+}
+func (m *RemoveTaskReply) XXX_Size() int {
+	return xxx_messageInfo_RemoveTaskReply.Size(m)
+}
+func (m *RemoveTaskReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_RemoveTaskReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RemoveTaskReply proto.InternalMessageInfo
+
+func (m *RemoveTaskReply) GetSuccess() bool {
+	if m != nil {
+		return m.Success
+	}
+	return false
+}
+
+func (m *RemoveTaskReply) GetTransactionID() string {
+	if m != nil {
+		return m.TransactionID
+	}
+	return ""
+}
+
+type ValidateTasksRequest struct {
+	Tasks                []*TransactionTask `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *ValidateTasksRequest) Reset()         { *m = ValidateTasksRequest{} }
+func (m *ValidateTasksRequest) String() string { return proto.CompactTextString(m) }
+func (*ValidateTasksRequest) ProtoMessage()    {}
+func (*ValidateTasksRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_36bf467611423882, []int{18}
+}
+
+func (m *ValidateTasksRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ValidateTasksRequest.Unmarshal(m, b)
+}
+func (m *ValidateTasksRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ValidateTasksRequest.Marshal(b, m, deterministic)
+}
+func (m *ValidateTasksRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ValidateTasksRequest.Merge(m, src)
+}
+func (m *ValidateTasksRequest) XXX_Size() int {
+	return xxx_messageInfo_ValidateTasksRequest.Size(m)
+}
+func (m *ValidateTasksRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ValidateTasksRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ValidateTasksRequest proto.InternalMessageInfo
+
+func (m *ValidateTasksRequest) GetTasks() []*TransactionTask {
+	if m != nil {
+		return m.Tasks
+	}
+	return nil
+}
+
+type ValidateTasksReply struct {
+	Success              bool             `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Violations           []*TaskViolation `protobuf:"bytes,2,rep,name=violations,proto3" json:"violations,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *ValidateTasksReply) Reset()         { *m = ValidateTasksReply{} }
+func (m *ValidateTasksReply) String() string { return proto.CompactTextString(m) }
+func (*ValidateTasksReply) ProtoMessage()    {}
+func (*ValidateTasksReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_36bf467611423882, []int{19}
+}
+
+func (m *ValidateTasksReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ValidateTasksReply.Unmarshal(m, b)
+}
+func (m *ValidateTasksReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ValidateTasksReply.Marshal(b, m, deterministic)
+}
+func (m *ValidateTasksReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ValidateTasksReply.Merge(m, src)
+}
+func (m *ValidateTasksReply) XXX_Size() int {
+	return xxx_messageInfo_ValidateTasksReply.Size(m)
+}
+func (m *ValidateTasksReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_ValidateTasksReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ValidateTasksReply proto.InternalMessageInfo
+
+func (m *ValidateTasksReply) GetSuccess() bool {
+	if m != nil {
+		return m.Success
+	}
+	return false
+}
+
+func (m *ValidateTasksReply) GetViolations() []*TaskViolation {
+	if m != nil {
+		return m.Violations
+	}
+	return nil
+}
+
+type TaskViolation struct {
+	TaskIndex            int32    `protobuf:"varint,1,opt,name=taskIndex,proto3" json:"taskIndex,omitempty"`
+	Field                string   `protobuf:"bytes,2,opt,name=field,proto3" json:"field,omitempty"`
+	Description          string   `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TaskViolation) Reset()         { *m = TaskViolation{} }
+func (m *TaskViolation) String() string { return proto.CompactTextString(m) }
+func (*TaskViolation) ProtoMessage()    {}
+func (*TaskViolation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_36bf467611423882, []int{20}
+}
+
+func (m *TaskViolation) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TaskViolation.Unmarshal(m, b)
+}
+func (m *TaskViolation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TaskViolation.Marshal(b, m, deterministic)
+}
+func (m *TaskViolation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TaskViolation.Merge(m, src)
+}
+func (m *TaskViolation) XXX_Size() int {
+	return xxx_messageInfo_TaskViolation.Size(m)
+}
+func (m *TaskViolation) XXX_DiscardUnknown() {
+	xxx_messageInfo_TaskViolation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TaskViolation proto.InternalMessageInfo
+
+func (m *TaskViolation) GetTaskIndex() int32 {
+	if m != nil {
+		return m.TaskIndex
+	}
+	return 0
+}
+
+func (m *TaskViolation) GetField() string {
+	if m != nil {
+		return m.Field
+	}
+	return ""
+}
+
+func (m *TaskViolation) GetDescription() string {
+	if m != nil {
+		return m.Description
+	}
+	return ""
+}
+
+type ForceResolveRequest struct {
+	TransactionID        string   `protobuf:"bytes,1,opt,name=transactionID,proto3" json:"transactionID,omitempty"`
+	Outcome              string   `protobuf:"bytes,2,opt,name=outcome,proto3" json:"outcome,omitempty"`
+	RedriveCancel        bool     `protobuf:"varint,3,opt,name=redriveCancel,proto3" json:"redriveCancel,omitempty"`
+	Operator             string   `protobuf:"bytes,4,opt,name=operator,proto3" json:"operator,omitempty"`
+	Reason               string   `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ForceResolveRequest) Reset()         { *m = ForceResolveRequest{} }
+func (m *ForceResolveRequest) String() string { return proto.CompactTextString(m) }
+func (*ForceResolveRequest) ProtoMessage()    {}
+func (*ForceResolveRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_36bf467611423882, []int{21}
+}
+
+func (m *ForceResolveRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ForceResolveRequest.Unmarshal(m, b)
+}
+func (m *ForceResolveRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ForceResolveRequest.Marshal(b, m, deterministic)
+}
+func (m *ForceResolveRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ForceResolveRequest.Merge(m, src)
+}
+func (m *ForceResolveRequest) XXX_Size() int {
+	return xxx_messageInfo_ForceResolveRequest.Size(m)
+}
+func (m *ForceResolveRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ForceResolveRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ForceResolveRequest proto.InternalMessageInfo
+
+func (m *ForceResolveRequest) GetTransactionID() string {
+	if m != nil {
+		return m.TransactionID
+	}
+	return ""
+}
+
+func (m *ForceResolveRequest) GetOutcome() string {
+	if m != nil {
+		return m.Outcome
+	}
+	return ""
+}
+
+func (m *ForceResolveRequest) GetRedriveCancel() bool {
+	if m != nil {
+		return m.RedriveCancel
+	}
+	return false
+}
+
+func (m *ForceResolveRequest) GetOperator() string {
+	if m != nil {
+		return m.Operator
+	}
+	return ""
+}
+
+func (m *ForceResolveRequest) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+type ForceResolveReply struct {
+	Success              bool     `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	TransactionID        string   `protobuf:"bytes,2,opt,name=transactionID,proto3" json:"transactionID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ForceResolveReply) Reset()         { *m = ForceResolveReply{} }
+func (m *ForceResolveReply) String() string { return proto.CompactTextString(m) }
+func (*ForceResolveReply) ProtoMessage()    {}
+func (*ForceResolveReply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_36bf467611423882, []int{22}
+}
+
+func (m *ForceResolveReply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ForceResolveReply.Unmarshal(m, b)
+}
+func (m *ForceResolveReply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ForceResolveReply.Marshal(b, m, deterministic)
+}
+func (m *ForceResolveReply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ForceResolveReply.Merge(m, src)
+}
+func (m *ForceResolveReply) XXX_Size() int {
+	return xxx_messageInfo_ForceResolveReply.Size(m)
+}
+func (m *ForceResolveReply) XXX_DiscardUnknown() {
+	xxx_messageInfo_ForceResolveReply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ForceResolveReply proto.InternalMessageInfo
+
+func (m *ForceResolveReply) GetSuccess() bool {
+	if m != nil {
+		return m.Success
+	}
+	return false
+}
+
+func (m *ForceResolveReply) GetTransactionID() string {
+	if m != nil {
+		return m.TransactionID
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*CreateTransactionRequest)(nil), "twist.CreateTransactionRequest")
+	proto.RegisterType((*CreateTransactionReply)(nil), "twist.CreateTransactionReply")
+	proto.RegisterType((*RegisterTasksRequest)(nil), "twist.RegisterTasksRequest")
+	proto.RegisterType((*RegisterTasksReply)(nil), "twist.RegisterTasksReply")
+	proto.RegisterType((*ConfirmTransactionRequest)(nil), "twist.ConfirmTransactionRequest")
+	proto.RegisterType((*ConfirmTransactionReply)(nil), "twist.ConfirmTransactionReply")
+	proto.RegisterType((*TransactionTask)(nil), "twist.TransactionTask")
+	proto.RegisterType((*TransactionTaskList)(nil), "twist.TransactionTaskList")
+	proto.RegisterType((*TransactionTaskAction)(nil), "twist.TransactionTaskAction")
+	proto.RegisterMapType((map[string]string)(nil), "twist.TransactionTaskAction.HeadersEntry")
+	proto.RegisterType((*CancelTransactionRequest)(nil), "twist.CancelTransactionRequest")
+	proto.RegisterType((*CancelTransactionReply)(nil), "twist.CancelTransactionReply")
+	proto.RegisterType((*SimulatedCall)(nil), "twist.SimulatedCall")
+	proto.RegisterMapType((map[string]string)(nil), "twist.SimulatedCall.HeadersEntry")
+	proto.RegisterType((*ListTasksRequest)(nil), "twist.ListTasksRequest")
+	proto.RegisterType((*ListTasksReply)(nil), "twist.ListTasksReply")
+	proto.RegisterType((*ReplaceTasksRequest)(nil), "twist.ReplaceTasksRequest")
+	proto.RegisterType((*ReplaceTasksReply)(nil), "twist.ReplaceTasksReply")
+	proto.RegisterType((*RemoveTaskRequest)(nil), "twist.RemoveTaskRequest")
+	proto.RegisterType((*RemoveTaskReply)(nil), "twist.RemoveTaskReply")
+	proto.RegisterType((*ValidateTasksRequest)(nil), "twist.ValidateTasksRequest")
+	proto.RegisterType((*ValidateTasksReply)(nil), "twist.ValidateTasksReply")
+	proto.RegisterType((*TaskViolation)(nil), "twist.TaskViolation")
+	proto.RegisterType((*ForceResolveRequest)(nil), "twist.ForceResolveRequest")
+	proto.RegisterType((*ForceResolveReply)(nil), "twist.ForceResolveReply")
+}
+
+func init() { proto.RegisterFile("commander.proto", fileDescriptor_36bf467611423882) }
+
+var fileDescriptor_36bf467611423882 = []byte{
+	// 973 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x57, 0x4f, 0x6f, 0x1b, 0x45,
+	0x14, 0x67, 0xd7, 0xb1, 0x1d, 0xbf, 0x34, 0x4d, 0x33, 0x49, 0xdd, 0xcd, 0xd2, 0xd2, 0xb0, 0xe2,
+	0x10, 0x24, 0xe4, 0x4a, 0x21, 0x42, 0x55, 0x11, 0x52, 0x91, 0x43, 0x45, 0x25, 0x2e, 0xdd, 0x86,
+	0xaa, 0xd7, 0xe9, 0xee, 0x24, 0x1d, 0xb2, 0xbb, 0xb3, 0xec, 0x8c, 0x4d, 0x7d, 0xe1, 0xc6, 0x01,
+	0x2e, 0x5c, 0xf9, 0x02, 0x9c, 0xb9, 0xf3, 0x21, 0xf8, 0x2c, 0x7c, 0x04, 0x34, 0x7f, 0xf6, 0x9f,
+	0x3d, 0x4e, 0x63, 0xe4, 0xaa, 0xb7, 0x79, 0x33, 0xef, 0xbd, 0xfd, 0xbd, 0x79, 0xbf, 0xf9, 0xcd,
+	0x2c, 0xec, 0x44, 0x2c, 0x4d, 0x71, 0x16, 0x93, 0x62, 0x94, 0x17, 0x4c, 0x30, 0xd4, 0x15, 0x3f,
+	0x51, 0x2e, 0xfc, 0xfb, 0x17, 0x8c, 0x5d, 0x24, 0xe4, 0x81, 0x9a, 0x7c, 0x35, 0x39, 0x7f, 0x20,
+	0x68, 0x4a, 0xb8, 0xc0, 0x69, 0xae, 0xfd, 0x82, 0x11, 0x78, 0xe3, 0x82, 0x60, 0x41, 0xce, 0x0a,
+	0x9c, 0x71, 0x1c, 0x09, 0xca, 0xb2, 0x90, 0xfc, 0x38, 0x21, 0x5c, 0x20, 0x04, 0x1b, 0x29, 0x8b,
+	0x89, 0xe7, 0x1c, 0x3a, 0x47, 0x83, 0x50, 0x8d, 0x83, 0x97, 0x30, 0xb4, 0xf8, 0xe7, 0xc9, 0x0c,
+	0x79, 0xd0, 0xe7, 0x93, 0x28, 0x22, 0x9c, 0xab, 0x80, 0xcd, 0xb0, 0x34, 0xd1, 0x27, 0xb0, 0x2d,
+	0x6a, 0xef, 0xa7, 0xa7, 0x9e, 0xab, 0x12, 0xb6, 0x27, 0x83, 0x1f, 0x60, 0x3f, 0x24, 0x17, 0x94,
+	0x0b, 0x52, 0x9c, 0x61, 0x7e, 0xc9, 0x4b, 0x14, 0x0b, 0xd1, 0x8e, 0x25, 0x1a, 0x7d, 0x06, 0x5d,
+	0x21, 0xa3, 0x3c, 0xf7, 0xb0, 0x73, 0xb4, 0x75, 0x3c, 0x1c, 0xa9, 0xfa, 0x47, 0x0d, 0x94, 0x32,
+	0x69, 0xa8, 0x9d, 0x82, 0xdf, 0x1c, 0x40, 0x73, 0x1f, 0x5b, 0x43, 0x09, 0xe8, 0x04, 0x60, 0x4a,
+	0x59, 0x82, 0xa5, 0xc9, 0xbd, 0x8e, 0x42, 0xb2, 0x5f, 0x22, 0xc1, 0xfc, 0xf2, 0x45, 0xb9, 0x18,
+	0x36, 0xfc, 0x82, 0x3f, 0x1d, 0x38, 0x18, 0xb3, 0xec, 0x9c, 0x16, 0xa9, 0xa5, 0x09, 0xef, 0xa0,
+	0x7c, 0x74, 0x02, 0x7d, 0xf2, 0x26, 0xa7, 0x05, 0x91, 0x20, 0x9d, 0xa3, 0xad, 0x63, 0x7f, 0xa4,
+	0x79, 0x32, 0x2a, 0x79, 0x32, 0x3a, 0x2b, 0x79, 0x12, 0x96, 0xae, 0xc1, 0x2f, 0x2e, 0xdc, 0xb1,
+	0xe1, 0x7c, 0x6f, 0x3b, 0x27, 0xa3, 0x54, 0x9a, 0xa8, 0xa0, 0xb9, 0xf0, 0x36, 0x5a, 0x51, 0xcf,
+	0x69, 0x3a, 0x49, 0xb0, 0x20, 0xf1, 0x18, 0x27, 0x49, 0xd8, 0xf0, 0x93, 0x58, 0xd9, 0x44, 0x44,
+	0x2c, 0x25, 0x5e, 0x57, 0x61, 0x29, 0x4d, 0x89, 0xd5, 0x0c, 0x4f, 0x89, 0xc0, 0x34, 0xf1, 0x7a,
+	0x1a, 0x6b, 0x6b, 0x32, 0xf8, 0xdd, 0x81, 0x9d, 0xb9, 0x8d, 0x45, 0x5f, 0x40, 0x3f, 0xd2, 0x5b,
+	0xa3, 0xea, 0xdf, 0x3a, 0xbe, 0x6b, 0xef, 0xc0, 0xd7, 0x6a, 0x14, 0x96, 0xce, 0xe8, 0x04, 0x7a,
+	0x11, 0xce, 0x22, 0x92, 0x78, 0xee, 0x35, 0xc2, 0x8c, 0x2f, 0xba, 0x09, 0x2e, 0x8d, 0x55, 0xeb,
+	0x06, 0xa1, 0x4b, 0xe3, 0x60, 0x0c, 0x7b, 0x73, 0x01, 0xdf, 0x51, 0x2e, 0x6a, 0x52, 0x38, 0xd7,
+	0x39, 0x13, 0xff, 0x3a, 0x70, 0xdb, 0xfa, 0x59, 0xa9, 0x03, 0x67, 0xb3, 0xbc, 0xd2, 0x01, 0x39,
+	0x46, 0x43, 0xe8, 0xa5, 0x44, 0xbc, 0x66, 0xb1, 0xe9, 0xa7, 0xb1, 0xd0, 0x2d, 0xe8, 0x4c, 0x0a,
+	0x6a, 0xb0, 0xc9, 0x21, 0x1a, 0x43, 0xff, 0x35, 0xc1, 0x31, 0x29, 0xb8, 0xe9, 0xd0, 0xa7, 0x57,
+	0xd5, 0x38, 0xfa, 0x56, 0xfb, 0x7e, 0x93, 0x89, 0x62, 0x16, 0x96, 0x91, 0xb2, 0x67, 0x39, 0x9e,
+	0x25, 0x0c, 0xc7, 0x65, 0xcf, 0x8c, 0xe9, 0x3f, 0x82, 0x1b, 0xcd, 0x10, 0x09, 0xe0, 0x92, 0xcc,
+	0x0c, 0x56, 0x39, 0x44, 0xfb, 0xd0, 0x9d, 0xe2, 0x64, 0x42, 0x0c, 0x52, 0x6d, 0x3c, 0x72, 0x1f,
+	0x3a, 0xc1, 0x63, 0xf0, 0xc6, 0x6a, 0x47, 0xff, 0xef, 0xb9, 0x0b, 0xfe, 0x71, 0x60, 0x68, 0x49,
+	0xb1, 0xa6, 0x23, 0xd1, 0x20, 0x77, 0x67, 0x75, 0x72, 0x6f, 0xbc, 0x85, 0xdc, 0x5d, 0x1b, 0xb9,
+	0xff, 0x70, 0x61, 0xbb, 0x95, 0x1d, 0xf9, 0xb0, 0xc9, 0xe5, 0x9e, 0x64, 0x91, 0x66, 0x40, 0x37,
+	0xac, 0x6c, 0xc9, 0x02, 0x49, 0x9e, 0xaa, 0x04, 0x63, 0xc9, 0x79, 0x5d, 0x87, 0x21, 0x82, 0xb1,
+	0x1a, 0xac, 0xd9, 0xb0, 0xb1, 0xa6, 0x5b, 0xb3, 0xe6, 0xcb, 0x9a, 0x35, 0x3d, 0x55, 0xfa, 0xc7,
+	0xb6, 0xd2, 0xdf, 0xce, 0x96, 0xfe, 0xfa, 0xd8, 0xf2, 0x10, 0x6e, 0xc9, 0x63, 0xb5, 0xfa, 0xe5,
+	0x14, 0xfc, 0x0c, 0x37, 0x1b, 0x91, 0xeb, 0x20, 0x47, 0x75, 0xb4, 0x3b, 0xd7, 0x39, 0xda, 0x14,
+	0xf6, 0xe4, 0x67, 0x71, 0x44, 0xde, 0xf9, 0xcd, 0xfa, 0xab, 0x03, 0xbb, 0xed, 0x6f, 0xbd, 0xbf,
+	0x8b, 0xf5, 0x99, 0x84, 0x92, 0xb2, 0xa9, 0x42, 0xb2, 0x5a, 0xd1, 0x4b, 0x88, 0x1d, 0x3c, 0x83,
+	0x9d, 0x66, 0xca, 0x75, 0xbc, 0x7b, 0x4e, 0x61, 0xff, 0x05, 0x4e, 0x68, 0x8c, 0x45, 0xb9, 0x63,
+	0x1a, 0xe8, 0x6a, 0xea, 0x1d, 0x03, 0x9a, 0xcb, 0x72, 0x35, 0xb6, 0xf6, 0x8e, 0xba, 0xd7, 0xdc,
+	0x51, 0x02, 0xdb, 0xad, 0x45, 0x74, 0x17, 0x06, 0x6a, 0x67, 0xb2, 0x98, 0xbc, 0x31, 0xea, 0x50,
+	0x4f, 0xc8, 0xb3, 0x74, 0x4e, 0x49, 0x52, 0xde, 0x11, 0xda, 0x40, 0x87, 0xb0, 0x15, 0x13, 0x2d,
+	0x57, 0xb5, 0x42, 0x34, 0xa7, 0x82, 0xbf, 0x1c, 0xd8, 0x7b, 0xc2, 0x8a, 0x88, 0x84, 0x84, 0xb3,
+	0x64, 0x4a, 0x56, 0xeb, 0x5d, 0x43, 0x02, 0xdd, 0x05, 0x09, 0x2c, 0x48, 0x5c, 0xd0, 0x29, 0xd1,
+	0x9a, 0xad, 0xbe, 0xbd, 0x19, 0xb6, 0x27, 0xa5, 0xe0, 0xb1, 0x9c, 0x14, 0x58, 0xb0, 0xc2, 0xc8,
+	0x54, 0x65, 0x4b, 0x5e, 0x14, 0x04, 0x73, 0x96, 0x19, 0xad, 0x32, 0x56, 0xf0, 0x1c, 0x76, 0xdb,
+	0x80, 0xd7, 0xc0, 0x8c, 0xe3, 0xbf, 0xbb, 0x30, 0x18, 0x97, 0xef, 0x7a, 0xf4, 0x3d, 0xec, 0x2e,
+	0xbc, 0xbc, 0xd1, 0x7d, 0xd3, 0xb2, 0x65, 0x6f, 0x78, 0xff, 0xde, 0x72, 0x87, 0x3c, 0x99, 0x05,
+	0x1f, 0xa0, 0xa7, 0xb0, 0xdd, 0x7a, 0x09, 0xa3, 0x0f, 0x4d, 0x84, 0xed, 0x31, 0xee, 0x1f, 0xd8,
+	0x17, 0x75, 0xaa, 0x97, 0x80, 0x16, 0xdf, 0x87, 0xe8, 0xb0, 0x44, 0xb0, 0xec, 0x89, 0xeb, 0x7f,
+	0x74, 0x85, 0x87, 0xce, 0x2c, 0x6b, 0x9f, 0xbf, 0x65, 0xeb, 0xda, 0x97, 0x5c, 0xe1, 0xfe, 0xbd,
+	0xe5, 0x0e, 0x3a, 0xed, 0x57, 0x30, 0xa8, 0x74, 0x19, 0xdd, 0x31, 0xde, 0xf3, 0x1a, 0xef, 0xdf,
+	0x5e, 0x5c, 0xd0, 0xe1, 0x4f, 0xe0, 0x46, 0x53, 0xea, 0x90, 0x5f, 0x6d, 0xce, 0x82, 0xd6, 0xfa,
+	0x9e, 0x75, 0x4d, 0xe7, 0x79, 0x0c, 0x50, 0x8b, 0x0a, 0xaa, 0x3d, 0xe7, 0xa4, 0xcb, 0x1f, 0x5a,
+	0x56, 0xaa, 0x26, 0xb6, 0x4e, 0x7f, 0xd5, 0x44, 0x9b, 0xb2, 0xf8, 0x07, 0xf6, 0xc5, 0xaa, 0xa8,
+	0x26, 0x93, 0xab, 0xa2, 0x2c, 0xe7, 0xd1, 0xf7, 0xac, 0x6b, 0x2a, 0xcf, 0xab, 0x9e, 0xfa, 0x95,
+	0xf8, 0xfc, 0xbf, 0x01, 0x00, 0x98, 0x81, 0x6f, 0xff, 0x9a, 0x0e, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// CommanderClient is the client API for Commander service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type CommanderClient interface {
+	CreateTransaction(ctx context.Context, in *CreateTransactionRequest, opts ...grpc.CallOption) (*CreateTransactionReply, error)
+	RegisterTasks(ctx context.Context, in *RegisterTasksRequest, opts ...grpc.CallOption) (*RegisterTasksReply, error)
+	ConfirmTransaction(ctx context.Context, in *ConfirmTransactionRequest, opts ...grpc.CallOption) (*ConfirmTransactionReply, error)
+	CancelTransaction(ctx context.Context, in *CancelTransactionRequest, opts ...grpc.CallOption) (*CancelTransactionReply, error)
+	ListTasks(ctx context.Context, in *ListTasksRequest, opts ...grpc.CallOption) (*ListTasksReply, error)
+	ReplaceTasks(ctx context.Context, in *ReplaceTasksRequest, opts ...grpc.CallOption) (*ReplaceTasksReply, error)
+	RemoveTask(ctx context.Context, in *RemoveTaskRequest, opts ...grpc.CallOption) (*RemoveTaskReply, error)
+	ValidateTasks(ctx context.Context, in *ValidateTasksRequest, opts ...grpc.CallOption) (*ValidateTasksReply, error)
+	ForceResolve(ctx context.Context, in *ForceResolveRequest, opts ...grpc.CallOption) (*ForceResolveReply, error)
+}
+
+type commanderClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewCommanderClient(cc *grpc.ClientConn) CommanderClient {
+	return &commanderClient{cc}
+}
+
+func (c *commanderClient) CreateTransaction(ctx context.Context, in *CreateTransactionRequest, opts ...grpc.CallOption) (*CreateTransactionReply, error) {
+	out := new(CreateTransactionReply)
+	err := c.cc.Invoke(ctx, "/twist.Commander/CreateTransaction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *commanderClient) RegisterTasks(ctx context.Context, in *RegisterTasksRequest, opts ...grpc.CallOption) (*RegisterTasksReply, error) {
+	out := new(RegisterTasksReply)
+	err := c.cc.Invoke(ctx, "/twist.Commander/RegisterTasks", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *commanderClient) ConfirmTransaction(ctx context.Context, in *ConfirmTransactionRequest, opts ...grpc.CallOption) (*ConfirmTransactionReply, error) {
+	out := new(ConfirmTransactionReply)
+	err := c.cc.Invoke(ctx, "/twist.Commander/ConfirmTransaction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *commanderClient) CancelTransaction(ctx context.Context, in *CancelTransactionRequest, opts ...grpc.CallOption) (*CancelTransactionReply, error) {
+	out := new(CancelTransactionReply)
+	err := c.cc.Invoke(ctx, "/twist.Commander/CancelTransaction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *commanderClient) ListTasks(ctx context.Context, in *ListTasksRequest, opts ...grpc.CallOption) (*ListTasksReply, error) {
+	out := new(ListTasksReply)
+	err := c.cc.Invoke(ctx, "/twist.Commander/ListTasks", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *commanderClient) ReplaceTasks(ctx context.Context, in *ReplaceTasksRequest, opts ...grpc.CallOption) (*ReplaceTasksReply, error) {
+	out := new(ReplaceTasksReply)
+	err := c.cc.Invoke(ctx, "/twist.Commander/ReplaceTasks", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *commanderClient) RemoveTask(ctx context.Context, in *RemoveTaskRequest, opts ...grpc.CallOption) (*RemoveTaskReply, error) {
+	out := new(RemoveTaskReply)
+	err := c.cc.Invoke(ctx, "/twist.Commander/RemoveTask", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *commanderClient) ValidateTasks(ctx context.Context, in *ValidateTasksRequest, opts ...grpc.CallOption) (*ValidateTasksReply, error) {
+	out := new(ValidateTasksReply)
+	err := c.cc.Invoke(ctx, "/twist.Commander/ValidateTasks", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *commanderClient) ForceResolve(ctx context.Context, in *ForceResolveRequest, opts ...grpc.CallOption) (*ForceResolveReply, error) {
+	out := new(ForceResolveReply)
+	err := c.cc.Invoke(ctx, "/twist.Commander/ForceResolve", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CommanderServer is the server API for Commander service.
+type CommanderServer interface {
+	CreateTransaction(context.Context, *CreateTransactionRequest) (*CreateTransactionReply, error)
+	RegisterTasks(context.Context, *RegisterTasksRequest) (*RegisterTasksReply, error)
+	ConfirmTransaction(context.Context, *ConfirmTransactionRequest) (*ConfirmTransactionReply, error)
+	CancelTransaction(context.Context, *CancelTransactionRequest) (*CancelTransactionReply, error)
+	ListTasks(context.Context, *ListTasksRequest) (*ListTasksReply, error)
+	ReplaceTasks(context.Context, *ReplaceTasksRequest) (*ReplaceTasksReply, error)
+	RemoveTask(context.Context, *RemoveTaskRequest) (*RemoveTaskReply, error)
+	ValidateTasks(context.Context, *ValidateTasksRequest) (*ValidateTasksReply, error)
+	ForceResolve(context.Context, *ForceResolveRequest) (*ForceResolveReply, error)
+}
+
+// UnimplementedCommanderServer can be embedded to have forward compatible implementations.
+type UnimplementedCommanderServer struct {
+}
+
+func (*UnimplementedCommanderServer) CreateTransaction(ctx context.Context, req *CreateTransactionRequest) (*CreateTransactionReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateTransaction not implemented")
+}
+func (*UnimplementedCommanderServer) RegisterTasks(ctx context.Context, req *RegisterTasksRequest) (*RegisterTasksReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterTasks not implemented")
+}
+func (*UnimplementedCommanderServer) ConfirmTransaction(ctx context.Context, req *ConfirmTransactionRequest) (*ConfirmTransactionReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmTransaction not implemented")
+}
+func (*UnimplementedCommanderServer) CancelTransaction(ctx context.Context, req *CancelTransactionRequest) (*CancelTransactionReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelTransaction not implemented")
+}
+func (*UnimplementedCommanderServer) ListTasks(ctx context.Context, req *ListTasksRequest) (*ListTasksReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTasks not implemented")
+}
+func (*UnimplementedCommanderServer) ReplaceTasks(ctx context.Context, req *ReplaceTasksRequest) (*ReplaceTasksReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReplaceTasks not implemented")
+}
+func (*UnimplementedCommanderServer) RemoveTask(ctx context.Context, req *RemoveTaskRequest) (*RemoveTaskReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveTask not implemented")
+}
+func (*UnimplementedCommanderServer) ValidateTasks(ctx context.Context, req *ValidateTasksRequest) (*ValidateTasksReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValidateTasks not implemented")
+}
+func (*UnimplementedCommanderServer) ForceResolve(ctx context.Context, req *ForceResolveRequest) (*ForceResolveReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ForceResolve not implemented")
+}
+
+func RegisterCommanderServer(s *grpc.Server, srv CommanderServer) {
+	s.RegisterService(&_Commander_serviceDesc, srv)
+}
+
+func _Commander_CreateTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommanderServer).CreateTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/twist.Commander/CreateTransaction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommanderServer).CreateTransaction(ctx, req.(*CreateTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Commander_RegisterTasks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterTasksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommanderServer).RegisterTasks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/twist.Commander/RegisterTasks",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommanderServer).RegisterTasks(ctx, req.(*RegisterTasksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Commander_ConfirmTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfirmTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommanderServer).ConfirmTransaction(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
@@ -727,6 +1586,96 @@ func _Commander_CancelTransaction_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Commander_ListTasks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTasksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommanderServer).ListTasks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/twist.Commander/ListTasks",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommanderServer).ListTasks(ctx, req.(*ListTasksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Commander_ReplaceTasks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplaceTasksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommanderServer).ReplaceTasks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/twist.Commander/ReplaceTasks",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommanderServer).ReplaceTasks(ctx, req.(*ReplaceTasksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Commander_RemoveTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommanderServer).RemoveTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/twist.Commander/RemoveTask",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommanderServer).RemoveTask(ctx, req.(*RemoveTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Commander_ValidateTasks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateTasksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommanderServer).ValidateTasks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/twist.Commander/ValidateTasks",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommanderServer).ValidateTasks(ctx, req.(*ValidateTasksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Commander_ForceResolve_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ForceResolveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommanderServer).ForceResolve(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/twist.Commander/ForceResolve",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommanderServer).ForceResolve(ctx, req.(*ForceResolveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Commander_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "twist.Commander",
 	HandlerType: (*CommanderServer)(nil),
@@ -747,6 +1696,26 @@ var _Commander_serviceDesc = grpc.ServiceDesc{
 			MethodName: "CancelTransaction",
 			Handler:    _Commander_CancelTransaction_Handler,
 		},
+		{
+			MethodName: "ListTasks",
+			Handler:    _Commander_ListTasks_Handler,
+		},
+		{
+			MethodName: "ReplaceTasks",
+			Handler:    _Commander_ReplaceTasks_Handler,
+		},
+		{
+			MethodName: "RemoveTask",
+			Handler:    _Commander_RemoveTask_Handler,
+		},
+		{
+			MethodName: "ValidateTasks",
+			Handler:    _Commander_ValidateTasks_Handler,
+		},
+		{
+			MethodName: "ForceResolve",
+			Handler:    _Commander_ForceResolve_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "commander.proto",
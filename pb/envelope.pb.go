@@ -0,0 +1,183 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: envelope.proto
+
+package twist
+
+import (
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	any "github.com/golang/protobuf/ptypes/any"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+// Envelope wraps every message on signal bus. Field numbers do not overlap
+// with bare messages, so legacy senders can be told apart during upgrades.
+type Envelope struct {
+	// "<major>.<minor>", receivers reject unknown major versions
+	SchemaVersion string `protobuf:"bytes,11,opt,name=schemaVersion,proto3" json:"schemaVersion,omitempty"`
+	// "protobuf" or "json"
+	Encoding  string               `protobuf:"bytes,12,opt,name=encoding,proto3" json:"encoding,omitempty"`
+	MessageID string               `protobuf:"bytes,13,opt,name=messageID,proto3" json:"messageID,omitempty"`
+	Timestamp *timestamp.Timestamp `protobuf:"bytes,14,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// Instance which sent the message
+	Source               string   `protobuf:"bytes,15,opt,name=source,proto3" json:"source,omitempty"`
+	Message              *any.Any `protobuf:"bytes,16,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Envelope) Reset()         { *m = Envelope{} }
+func (m *Envelope) String() string { return proto.CompactTextString(m) }
+func (*Envelope) ProtoMessage()    {}
+func (*Envelope) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ee266e8c558e9dc5, []int{0}
+}
+
+func (m *Envelope) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Envelope.Unmarshal(m, b)
+}
+func (m *Envelope) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Envelope.Marshal(b, m, deterministic)
+}
+func (m *Envelope) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Envelope.Merge(m, src)
+}
+func (m *Envelope) XXX_Size() int {
+	return xxx_messageInfo_Envelope.Size(m)
+}
+func (m *Envelope) XXX_DiscardUnknown() {
+	xxx_messageInfo_Envelope.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Envelope proto.InternalMessageInfo
+
+func (m *Envelope) GetSchemaVersion() string {
+	if m != nil {
+		return m.SchemaVersion
+	}
+	return ""
+}
+
+func (m *Envelope) GetEncoding() string {
+	if m != nil {
+		return m.Encoding
+	}
+	return ""
+}
+
+func (m *Envelope) GetMessageID() string {
+	if m != nil {
+		return m.MessageID
+	}
+	return ""
+}
+
+func (m *Envelope) GetTimestamp() *timestamp.Timestamp {
+	if m != nil {
+		return m.Timestamp
+	}
+	return nil
+}
+
+func (m *Envelope) GetSource() string {
+	if m != nil {
+		return m.Source
+	}
+	return ""
+}
+
+func (m *Envelope) GetMessage() *any.Any {
+	if m != nil {
+		return m.Message
+	}
+	return nil
+}
+
+// Signal carries subject of a message over streaming server, where every
+// signal goes through one channel
+type Signal struct {
+	Subject              string   `protobuf:"bytes,1,opt,name=subject,proto3" json:"subject,omitempty"`
+	Data                 []byte   `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Signal) Reset()         { *m = Signal{} }
+func (m *Signal) String() string { return proto.CompactTextString(m) }
+func (*Signal) ProtoMessage()    {}
+func (*Signal) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ee266e8c558e9dc5, []int{1}
+}
+
+func (m *Signal) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Signal.Unmarshal(m, b)
+}
+func (m *Signal) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Signal.Marshal(b, m, deterministic)
+}
+func (m *Signal) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Signal.Merge(m, src)
+}
+func (m *Signal) XXX_Size() int {
+	return xxx_messageInfo_Signal.Size(m)
+}
+func (m *Signal) XXX_DiscardUnknown() {
+	xxx_messageInfo_Signal.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Signal proto.InternalMessageInfo
+
+func (m *Signal) GetSubject() string {
+	if m != nil {
+		return m.Subject
+	}
+	return ""
+}
+
+func (m *Signal) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*Envelope)(nil), "twist.Envelope")
+	proto.RegisterType((*Signal)(nil), "twist.Signal")
+}
+
+func init() { proto.RegisterFile("envelope.proto", fileDescriptor_ee266e8c558e9dc5) }
+
+var fileDescriptor_ee266e8c558e9dc5 = []byte{
+	// 250 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x64, 0x8e, 0xcd, 0x4a, 0xc4, 0x30,
+	0x14, 0x85, 0x89, 0x68, 0x67, 0x7a, 0xe7, 0x47, 0xb9, 0x88, 0xc4, 0x22, 0x58, 0x06, 0x17, 0x5d,
+	0x65, 0x40, 0x41, 0xdc, 0x0a, 0xba, 0x70, 0x5b, 0xc5, 0x7d, 0xda, 0xb9, 0xc6, 0x48, 0x9b, 0x94,
+	0x26, 0x55, 0xe6, 0x9d, 0x7d, 0x08, 0x21, 0x6d, 0x67, 0x50, 0x77, 0x39, 0xe7, 0x7e, 0x7c, 0x39,
+	0xb0, 0x24, 0xf3, 0x49, 0x95, 0x6d, 0x48, 0x34, 0xad, 0xf5, 0x16, 0x8f, 0xfc, 0x97, 0x76, 0x3e,
+	0x39, 0x57, 0xd6, 0xaa, 0x8a, 0xd6, 0xa1, 0x2c, 0xba, 0xb7, 0xb5, 0x34, 0xdb, 0x9e, 0x48, 0x2e,
+	0xff, 0x9e, 0xbc, 0xae, 0xc9, 0x79, 0x59, 0x37, 0x3d, 0xb0, 0xfa, 0x66, 0x30, 0x7d, 0x1c, 0xac,
+	0x78, 0x05, 0x0b, 0x57, 0xbe, 0x53, 0x2d, 0x5f, 0xa9, 0x75, 0xda, 0x1a, 0x3e, 0x4b, 0x59, 0x16,
+	0xe7, 0xbf, 0x4b, 0x4c, 0x60, 0x4a, 0xa6, 0xb4, 0x1b, 0x6d, 0x14, 0x9f, 0x07, 0x60, 0x97, 0xf1,
+	0x02, 0xe2, 0x9a, 0x9c, 0x93, 0x8a, 0x9e, 0x1e, 0xf8, 0x22, 0x1c, 0xf7, 0x05, 0xde, 0x41, 0xbc,
+	0xfb, 0x9f, 0x2f, 0x53, 0x96, 0xcd, 0xae, 0x13, 0xd1, 0x2f, 0x14, 0xe3, 0x42, 0xf1, 0x32, 0x12,
+	0xf9, 0x1e, 0xc6, 0x33, 0x88, 0x9c, 0xed, 0xda, 0x92, 0xf8, 0x71, 0x90, 0x0e, 0x09, 0x05, 0x4c,
+	0x06, 0x3d, 0x3f, 0x09, 0xbe, 0xd3, 0x7f, 0xbe, 0x7b, 0xb3, 0xcd, 0x47, 0x68, 0x75, 0x0b, 0xd1,
+	0xb3, 0x56, 0x46, 0x56, 0xc8, 0x61, 0xe2, 0xba, 0xe2, 0x83, 0x4a, 0xcf, 0x59, 0x50, 0x8e, 0x11,
+	0x11, 0x0e, 0x37, 0xd2, 0x4b, 0x7e, 0x90, 0xb2, 0x6c, 0x9e, 0x87, 0x77, 0x11, 0x05, 0xdd, 0xcd,
+	0xcf, 0x00, 0xa4, 0x9c, 0x40, 0x06, 0x82, 0x01, 0x00, 0x00,
+}
@@ -24,9 +24,18 @@ var _ = math.Inf
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
 type TransactionCommand struct {
-	TransactionID        string   `protobuf:"bytes,1,opt,name=transactionID,proto3" json:"transactionID,omitempty"`
-	Command              string   `protobuf:"bytes,2,opt,name=command,proto3" json:"command,omitempty"`
-	Payload              *any.Any `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`
+	TransactionID string   `protobuf:"bytes,1,opt,name=transactionID,proto3" json:"transactionID,omitempty"`
+	Command       string   `protobuf:"bytes,2,opt,name=command,proto3" json:"command,omitempty"`
+	Payload       *any.Any `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`
+	// Retransmitted command keeps its ID, runner handles it only once and
+	// acknowledges every copy with "CommandReceived" event
+	CommandID string `protobuf:"bytes,4,opt,name=commandID,proto3" json:"commandID,omitempty"`
+	Attempt   int32  `protobuf:"varint,5,opt,name=attempt,proto3" json:"attempt,omitempty"`
+	// Commander instance which issued the command
+	InstanceID string `protobuf:"bytes,6,opt,name=instanceID,proto3" json:"instanceID,omitempty"`
+	// When set, runner which supports it emits events caused by the command to
+	// this subject instead of events subject of transaction
+	ReplySubject         string   `protobuf:"bytes,7,opt,name=replySubject,proto3" json:"replySubject,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -78,6 +87,34 @@ func (m *TransactionCommand) GetPayload() *any.Any {
 	return nil
 }
 
+func (m *TransactionCommand) GetCommandID() string {
+	if m != nil {
+		return m.CommandID
+	}
+	return ""
+}
+
+func (m *TransactionCommand) GetAttempt() int32 {
+	if m != nil {
+		return m.Attempt
+	}
+	return 0
+}
+
+func (m *TransactionCommand) GetInstanceID() string {
+	if m != nil {
+		return m.InstanceID
+	}
+	return ""
+}
+
+func (m *TransactionCommand) GetReplySubject() string {
+	if m != nil {
+		return m.ReplySubject
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*TransactionCommand)(nil), "twist.TransactionCommand")
 }
@@ -85,18 +122,22 @@ func init() {
 func init() { proto.RegisterFile("runner.proto", fileDescriptor_48eceea7e2abc593) }
 
 var fileDescriptor_48eceea7e2abc593 = []byte{
-	// 163 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0x29, 0x2a, 0xcd, 0xcb,
-	0x4b, 0x2d, 0xd2, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x2d, 0x29, 0xcf, 0x2c, 0x2e, 0x91,
-	0x92, 0x4c, 0xcf, 0xcf, 0x4f, 0xcf, 0x49, 0xd5, 0x07, 0x0b, 0x26, 0x95, 0xa6, 0xe9, 0x27, 0xe6,
-	0x55, 0x42, 0x54, 0x28, 0xb5, 0x30, 0x72, 0x09, 0x85, 0x14, 0x25, 0xe6, 0x15, 0x27, 0x26, 0x97,
-	0x64, 0xe6, 0xe7, 0x39, 0xe7, 0xe7, 0xe6, 0x26, 0xe6, 0xa5, 0x08, 0xa9, 0x70, 0xf1, 0x96, 0x20,
-	0x44, 0x3d, 0x5d, 0x24, 0x18, 0x15, 0x18, 0x35, 0x38, 0x83, 0x50, 0x05, 0x85, 0x24, 0xb8, 0xd8,
-	0x93, 0x21, 0x1a, 0x24, 0x98, 0xc0, 0xf2, 0x30, 0xae, 0x90, 0x1e, 0x17, 0x7b, 0x41, 0x62, 0x65,
-	0x4e, 0x7e, 0x62, 0x8a, 0x04, 0xb3, 0x02, 0xa3, 0x06, 0xb7, 0x91, 0x88, 0x1e, 0xc4, 0x0d, 0x7a,
-	0x30, 0x37, 0xe8, 0x39, 0xe6, 0x55, 0x06, 0xc1, 0x14, 0x19, 0x71, 0x70, 0xb1, 0x05, 0x81, 0x1d,
-	0x9e, 0xc4, 0x06, 0x56, 0x60, 0x0c, 0x08, 0x00, 0x00, 0xff, 0xff, 0xe7, 0xff, 0x74, 0x58, 0xc9,
-	0x00, 0x00, 0x00,
+	// 227 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x54, 0x90, 0x41, 0x4f, 0x83, 0x40,
+	0x10, 0x85, 0x43, 0x15, 0xb0, 0x63, 0xbd, 0x6c, 0x3c, 0x8c, 0xc6, 0x18, 0xd2, 0x78, 0xe0, 0xb4,
+	0x4d, 0xea, 0x2f, 0x30, 0x72, 0xe1, 0x8a, 0xfe, 0x81, 0x81, 0xae, 0x0d, 0x06, 0x66, 0xc9, 0x32,
+	0xc4, 0x70, 0xf3, 0xa7, 0x1b, 0x17, 0x37, 0xb5, 0xc7, 0xf7, 0xcd, 0x7b, 0x33, 0x2f, 0x03, 0x1b,
+	0x37, 0x31, 0x1b, 0xa7, 0x07, 0x67, 0xc5, 0xaa, 0x58, 0xbe, 0xda, 0x51, 0xee, 0xef, 0x8e, 0xd6,
+	0x1e, 0x3b, 0xb3, 0xf3, 0xb0, 0x9e, 0x3e, 0x76, 0xc4, 0xf3, 0xe2, 0xd8, 0x7e, 0xaf, 0x40, 0xbd,
+	0x3b, 0xe2, 0x91, 0x1a, 0x69, 0x2d, 0xbf, 0xda, 0xbe, 0x27, 0x3e, 0xa8, 0x27, 0xb8, 0x91, 0x13,
+	0x2d, 0x0b, 0x8c, 0xb2, 0x28, 0x5f, 0x57, 0xe7, 0x50, 0x21, 0xa4, 0xcd, 0x12, 0xc0, 0x95, 0x9f,
+	0x07, 0xa9, 0x34, 0xa4, 0x03, 0xcd, 0x9d, 0xa5, 0x03, 0x5e, 0x64, 0x51, 0x7e, 0xbd, 0xbf, 0xd5,
+	0x4b, 0x07, 0x1d, 0x3a, 0xe8, 0x17, 0x9e, 0xab, 0x60, 0x52, 0x0f, 0xb0, 0xfe, 0x8b, 0x96, 0x05,
+	0x5e, 0xfa, 0x5d, 0x27, 0xf0, 0x7b, 0x87, 0x44, 0x4c, 0x3f, 0x08, 0xc6, 0x59, 0x94, 0xc7, 0x55,
+	0x90, 0xea, 0x11, 0xa0, 0xe5, 0x51, 0x88, 0x1b, 0x53, 0x16, 0x98, 0xf8, 0xe0, 0x3f, 0xa2, 0xb6,
+	0xb0, 0x71, 0x66, 0xe8, 0xe6, 0xb7, 0xa9, 0xfe, 0x34, 0x8d, 0x60, 0xea, 0x1d, 0x67, 0x6c, 0x7f,
+	0x05, 0x49, 0xe5, 0x9f, 0x56, 0x27, 0xbe, 0xdc, 0xf3, 0xcf, 0x00, 0x2c, 0x75, 0x1f, 0xdd, 0x45,
+	0x01, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
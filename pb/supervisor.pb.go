@@ -24,6 +24,70 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
+type TransactionEventType int32
+
+const (
+	TransactionEventType_EVENT_UNKNOWN            TransactionEventType = 0
+	TransactionEventType_EVENT_ASSIGNED           TransactionEventType = 1
+	TransactionEventType_EVENT_TASKS_REGISTERED   TransactionEventType = 2
+	TransactionEventType_EVENT_TASKS_LISTED       TransactionEventType = 3
+	TransactionEventType_EVENT_TASKS_REPLACED     TransactionEventType = 4
+	TransactionEventType_EVENT_TASK_REMOVED       TransactionEventType = 5
+	TransactionEventType_EVENT_TASK_NOT_FOUND     TransactionEventType = 6
+	TransactionEventType_EVENT_CONFIRMED          TransactionEventType = 7
+	TransactionEventType_EVENT_CANCELED           TransactionEventType = 8
+	TransactionEventType_EVENT_TIMEOUT            TransactionEventType = 9
+	TransactionEventType_EVENT_HEURISTIC_MIXED    TransactionEventType = 10
+	TransactionEventType_EVENT_HEURISTIC_COMMIT   TransactionEventType = 11
+	TransactionEventType_EVENT_HEURISTIC_ROLLBACK TransactionEventType = 12
+	TransactionEventType_EVENT_COMMAND_RECEIVED   TransactionEventType = 13
+	TransactionEventType_EVENT_STATE_REPORTED     TransactionEventType = 14
+)
+
+var TransactionEventType_name = map[int32]string{
+	0:  "EVENT_UNKNOWN",
+	1:  "EVENT_ASSIGNED",
+	2:  "EVENT_TASKS_REGISTERED",
+	3:  "EVENT_TASKS_LISTED",
+	4:  "EVENT_TASKS_REPLACED",
+	5:  "EVENT_TASK_REMOVED",
+	6:  "EVENT_TASK_NOT_FOUND",
+	7:  "EVENT_CONFIRMED",
+	8:  "EVENT_CANCELED",
+	9:  "EVENT_TIMEOUT",
+	10: "EVENT_HEURISTIC_MIXED",
+	11: "EVENT_HEURISTIC_COMMIT",
+	12: "EVENT_HEURISTIC_ROLLBACK",
+	13: "EVENT_COMMAND_RECEIVED",
+	14: "EVENT_STATE_REPORTED",
+}
+
+var TransactionEventType_value = map[string]int32{
+	"EVENT_UNKNOWN":            0,
+	"EVENT_ASSIGNED":           1,
+	"EVENT_TASKS_REGISTERED":   2,
+	"EVENT_TASKS_LISTED":       3,
+	"EVENT_TASKS_REPLACED":     4,
+	"EVENT_TASK_REMOVED":       5,
+	"EVENT_TASK_NOT_FOUND":     6,
+	"EVENT_CONFIRMED":          7,
+	"EVENT_CANCELED":           8,
+	"EVENT_TIMEOUT":            9,
+	"EVENT_HEURISTIC_MIXED":    10,
+	"EVENT_HEURISTIC_COMMIT":   11,
+	"EVENT_HEURISTIC_ROLLBACK": 12,
+	"EVENT_COMMAND_RECEIVED":   13,
+	"EVENT_STATE_REPORTED":     14,
+}
+
+func (x TransactionEventType) String() string {
+	return proto.EnumName(TransactionEventType_name, int32(x))
+}
+
+func (TransactionEventType) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_b8b9452d77b1c7d2, []int{0}
+}
+
 type TransactionRequest struct {
 	TransactionID        string   `protobuf:"bytes,1,opt,name=transactionID,proto3" json:"transactionID,omitempty"`
 	Mode                 string   `protobuf:"bytes,2,opt,name=mode,proto3" json:"mode,omitempty"`
@@ -72,13 +136,26 @@ func (m *TransactionRequest) GetMode() string {
 }
 
 type TransactionEvent struct {
-	TransactionID        string   `protobuf:"bytes,1,opt,name=transactionID,proto3" json:"transactionID,omitempty"`
-	RunnerID             string   `protobuf:"bytes,2,opt,name=RunnerID,proto3" json:"RunnerID,omitempty"`
-	EventName            string   `protobuf:"bytes,3,opt,name=eventName,proto3" json:"eventName,omitempty"`
-	Payload              string   `protobuf:"bytes,4,opt,name=payload,proto3" json:"payload,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	TransactionID string `protobuf:"bytes,1,opt,name=transactionID,proto3" json:"transactionID,omitempty"`
+	RunnerID      string `protobuf:"bytes,2,opt,name=RunnerID,proto3" json:"RunnerID,omitempty"`
+	// Legacy string form, kept while runners migrate to type and detail
+	EventName string `protobuf:"bytes,3,opt,name=eventName,proto3" json:"eventName,omitempty"`
+	Payload   string `protobuf:"bytes,4,opt,name=payload,proto3" json:"payload,omitempty"`
+	// Increases by one for every event of transaction, zero means the event is
+	// not sequenced
+	Sequence uint64               `protobuf:"varint,5,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Type     TransactionEventType `protobuf:"varint,6,opt,name=type,proto3,enum=twist.TransactionEventType" json:"type,omitempty"`
+	// Types that are valid to be assigned to Detail:
+	//	*TransactionEvent_TaskResults
+	//	*TransactionEvent_Failure
+	//	*TransactionEvent_Runner
+	//	*TransactionEvent_TaskList
+	//	*TransactionEvent_State
+	//	*TransactionEvent_Receipt
+	Detail               isTransactionEvent_Detail `protobuf_oneof:"detail"`
+	XXX_NoUnkeyedLiteral struct{}                  `json:"-"`
+	XXX_unrecognized     []byte                    `json:"-"`
+	XXX_sizecache        int32                     `json:"-"`
 }
 
 func (m *TransactionEvent) Reset()         { *m = TransactionEvent{} }
@@ -134,6 +211,434 @@ func (m *TransactionEvent) GetPayload() string {
 	return ""
 }
 
+func (m *TransactionEvent) GetSequence() uint64 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+func (m *TransactionEvent) GetType() TransactionEventType {
+	if m != nil {
+		return m.Type
+	}
+	return TransactionEventType_EVENT_UNKNOWN
+}
+
+type isTransactionEvent_Detail interface {
+	isTransactionEvent_Detail()
+}
+
+type TransactionEvent_TaskResults struct {
+	TaskResults *TaskResults `protobuf:"bytes,7,opt,name=taskResults,proto3,oneof"`
+}
+
+type TransactionEvent_Failure struct {
+	Failure *FailureReason `protobuf:"bytes,8,opt,name=failure,proto3,oneof"`
+}
+
+type TransactionEvent_Runner struct {
+	Runner *RunnerInfo `protobuf:"bytes,9,opt,name=runner,proto3,oneof"`
+}
+
+type TransactionEvent_TaskList struct {
+	TaskList *TransactionTaskList `protobuf:"bytes,10,opt,name=taskList,proto3,oneof"`
+}
+
+type TransactionEvent_State struct {
+	State *TransactionState `protobuf:"bytes,17,opt,name=state,proto3,oneof"`
+}
+
+type TransactionEvent_Receipt struct {
+	Receipt *CommandReceipt `protobuf:"bytes,18,opt,name=receipt,proto3,oneof"`
+}
+
+func (*TransactionEvent_TaskResults) isTransactionEvent_Detail() {}
+
+func (*TransactionEvent_Failure) isTransactionEvent_Detail() {}
+
+func (*TransactionEvent_Runner) isTransactionEvent_Detail() {}
+
+func (*TransactionEvent_TaskList) isTransactionEvent_Detail() {}
+
+func (*TransactionEvent_State) isTransactionEvent_Detail() {}
+
+func (*TransactionEvent_Receipt) isTransactionEvent_Detail() {}
+
+func (m *TransactionEvent) GetDetail() isTransactionEvent_Detail {
+	if m != nil {
+		return m.Detail
+	}
+	return nil
+}
+
+func (m *TransactionEvent) GetTaskResults() *TaskResults {
+	if x, ok := m.GetDetail().(*TransactionEvent_TaskResults); ok {
+		return x.TaskResults
+	}
+	return nil
+}
+
+func (m *TransactionEvent) GetFailure() *FailureReason {
+	if x, ok := m.GetDetail().(*TransactionEvent_Failure); ok {
+		return x.Failure
+	}
+	return nil
+}
+
+func (m *TransactionEvent) GetRunner() *RunnerInfo {
+	if x, ok := m.GetDetail().(*TransactionEvent_Runner); ok {
+		return x.Runner
+	}
+	return nil
+}
+
+func (m *TransactionEvent) GetTaskList() *TransactionTaskList {
+	if x, ok := m.GetDetail().(*TransactionEvent_TaskList); ok {
+		return x.TaskList
+	}
+	return nil
+}
+
+func (m *TransactionEvent) GetState() *TransactionState {
+	if x, ok := m.GetDetail().(*TransactionEvent_State); ok {
+		return x.State
+	}
+	return nil
+}
+
+func (m *TransactionEvent) GetReceipt() *CommandReceipt {
+	if x, ok := m.GetDetail().(*TransactionEvent_Receipt); ok {
+		return x.Receipt
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*TransactionEvent) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*TransactionEvent_TaskResults)(nil),
+		(*TransactionEvent_Failure)(nil),
+		(*TransactionEvent_Runner)(nil),
+		(*TransactionEvent_TaskList)(nil),
+		(*TransactionEvent_State)(nil),
+		(*TransactionEvent_Receipt)(nil),
+	}
+}
+
+// Results of tasks, reported with outcome of transaction
+type TaskResults struct {
+	Results              []*TaskResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *TaskResults) Reset()         { *m = TaskResults{} }
+func (m *TaskResults) String() string { return proto.CompactTextString(m) }
+func (*TaskResults) ProtoMessage()    {}
+func (*TaskResults) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b8b9452d77b1c7d2, []int{2}
+}
+
+func (m *TaskResults) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TaskResults.Unmarshal(m, b)
+}
+func (m *TaskResults) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TaskResults.Marshal(b, m, deterministic)
+}
+func (m *TaskResults) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TaskResults.Merge(m, src)
+}
+func (m *TaskResults) XXX_Size() int {
+	return xxx_messageInfo_TaskResults.Size(m)
+}
+func (m *TaskResults) XXX_DiscardUnknown() {
+	xxx_messageInfo_TaskResults.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TaskResults proto.InternalMessageInfo
+
+func (m *TaskResults) GetResults() []*TaskResult {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+type TaskResult struct {
+	TaskID string `protobuf:"bytes,1,opt,name=taskID,proto3" json:"taskID,omitempty"`
+	// "confirm" or "cancel"
+	Action               string   `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	Success              bool     `protobuf:"varint,3,opt,name=success,proto3" json:"success,omitempty"`
+	StatusCode           int32    `protobuf:"varint,4,opt,name=statusCode,proto3" json:"statusCode,omitempty"`
+	Error                string   `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TaskResult) Reset()         { *m = TaskResult{} }
+func (m *TaskResult) String() string { return proto.CompactTextString(m) }
+func (*TaskResult) ProtoMessage()    {}
+func (*TaskResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b8b9452d77b1c7d2, []int{3}
+}
+
+func (m *TaskResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TaskResult.Unmarshal(m, b)
+}
+func (m *TaskResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TaskResult.Marshal(b, m, deterministic)
+}
+func (m *TaskResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TaskResult.Merge(m, src)
+}
+func (m *TaskResult) XXX_Size() int {
+	return xxx_messageInfo_TaskResult.Size(m)
+}
+func (m *TaskResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_TaskResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TaskResult proto.InternalMessageInfo
+
+func (m *TaskResult) GetTaskID() string {
+	if m != nil {
+		return m.TaskID
+	}
+	return ""
+}
+
+func (m *TaskResult) GetAction() string {
+	if m != nil {
+		return m.Action
+	}
+	return ""
+}
+
+func (m *TaskResult) GetSuccess() bool {
+	if m != nil {
+		return m.Success
+	}
+	return false
+}
+
+func (m *TaskResult) GetStatusCode() int32 {
+	if m != nil {
+		return m.StatusCode
+	}
+	return 0
+}
+
+func (m *TaskResult) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type FailureReason struct {
+	Code                 string   `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FailureReason) Reset()         { *m = FailureReason{} }
+func (m *FailureReason) String() string { return proto.CompactTextString(m) }
+func (*FailureReason) ProtoMessage()    {}
+func (*FailureReason) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b8b9452d77b1c7d2, []int{4}
+}
+
+func (m *FailureReason) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FailureReason.Unmarshal(m, b)
+}
+func (m *FailureReason) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FailureReason.Marshal(b, m, deterministic)
+}
+func (m *FailureReason) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FailureReason.Merge(m, src)
+}
+func (m *FailureReason) XXX_Size() int {
+	return xxx_messageInfo_FailureReason.Size(m)
+}
+func (m *FailureReason) XXX_DiscardUnknown() {
+	xxx_messageInfo_FailureReason.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FailureReason proto.InternalMessageInfo
+
+func (m *FailureReason) GetCode() string {
+	if m != nil {
+		return m.Code
+	}
+	return ""
+}
+
+func (m *FailureReason) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+// Runner which the transaction was assigned to
+type RunnerInfo struct {
+	RunnerID             string   `protobuf:"bytes,1,opt,name=runnerID,proto3" json:"runnerID,omitempty"`
+	Host                 string   `protobuf:"bytes,2,opt,name=host,proto3" json:"host,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RunnerInfo) Reset()         { *m = RunnerInfo{} }
+func (m *RunnerInfo) String() string { return proto.CompactTextString(m) }
+func (*RunnerInfo) ProtoMessage()    {}
+func (*RunnerInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b8b9452d77b1c7d2, []int{5}
+}
+
+func (m *RunnerInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RunnerInfo.Unmarshal(m, b)
+}
+func (m *RunnerInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RunnerInfo.Marshal(b, m, deterministic)
+}
+func (m *RunnerInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RunnerInfo.Merge(m, src)
+}
+func (m *RunnerInfo) XXX_Size() int {
+	return xxx_messageInfo_RunnerInfo.Size(m)
+}
+func (m *RunnerInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_RunnerInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RunnerInfo proto.InternalMessageInfo
+
+func (m *RunnerInfo) GetRunnerID() string {
+	if m != nil {
+		return m.RunnerID
+	}
+	return ""
+}
+
+func (m *RunnerInfo) GetHost() string {
+	if m != nil {
+		return m.Host
+	}
+	return ""
+}
+
+// Acknowledgement of command
+type CommandReceipt struct {
+	CommandID            string   `protobuf:"bytes,1,opt,name=commandID,proto3" json:"commandID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CommandReceipt) Reset()         { *m = CommandReceipt{} }
+func (m *CommandReceipt) String() string { return proto.CompactTextString(m) }
+func (*CommandReceipt) ProtoMessage()    {}
+func (*CommandReceipt) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b8b9452d77b1c7d2, []int{6}
+}
+
+func (m *CommandReceipt) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CommandReceipt.Unmarshal(m, b)
+}
+func (m *CommandReceipt) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CommandReceipt.Marshal(b, m, deterministic)
+}
+func (m *CommandReceipt) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CommandReceipt.Merge(m, src)
+}
+func (m *CommandReceipt) XXX_Size() int {
+	return xxx_messageInfo_CommandReceipt.Size(m)
+}
+func (m *CommandReceipt) XXX_DiscardUnknown() {
+	xxx_messageInfo_CommandReceipt.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CommandReceipt proto.InternalMessageInfo
+
+func (m *CommandReceipt) GetCommandID() string {
+	if m != nil {
+		return m.CommandID
+	}
+	return ""
+}
+
+// Reply to "queryState" command, sent as detail of EVENT_STATE_REPORTED event
+// or as JSON payload by legacy runners
+type TransactionState struct {
+	TransactionID string `protobuf:"bytes,1,opt,name=transactionID,proto3" json:"transactionID,omitempty"`
+	// Current state, the outcome like "Confirmed" once transaction is finished
+	State                string   `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
+	Sequence             uint64   `protobuf:"varint,3,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Payload              string   `protobuf:"bytes,4,opt,name=payload,proto3" json:"payload,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TransactionState) Reset()         { *m = TransactionState{} }
+func (m *TransactionState) String() string { return proto.CompactTextString(m) }
+func (*TransactionState) ProtoMessage()    {}
+func (*TransactionState) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b8b9452d77b1c7d2, []int{7}
+}
+
+func (m *TransactionState) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TransactionState.Unmarshal(m, b)
+}
+func (m *TransactionState) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TransactionState.Marshal(b, m, deterministic)
+}
+func (m *TransactionState) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TransactionState.Merge(m, src)
+}
+func (m *TransactionState) XXX_Size() int {
+	return xxx_messageInfo_TransactionState.Size(m)
+}
+func (m *TransactionState) XXX_DiscardUnknown() {
+	xxx_messageInfo_TransactionState.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TransactionState proto.InternalMessageInfo
+
+func (m *TransactionState) GetTransactionID() string {
+	if m != nil {
+		return m.TransactionID
+	}
+	return ""
+}
+
+func (m *TransactionState) GetState() string {
+	if m != nil {
+		return m.State
+	}
+	return ""
+}
+
+func (m *TransactionState) GetSequence() uint64 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+func (m *TransactionState) GetPayload() string {
+	if m != nil {
+		return m.Payload
+	}
+	return ""
+}
+
 type PrepareTransactionRequest struct {
 	TransactionID        string   `protobuf:"bytes,1,opt,name=transactionID,proto3" json:"transactionID,omitempty"`
 	Mode                 string   `protobuf:"bytes,2,opt,name=mode,proto3" json:"mode,omitempty"`
@@ -146,7 +651,7 @@ func (m *PrepareTransactionRequest) Reset()         { *m = PrepareTransactionReq
 func (m *PrepareTransactionRequest) String() string { return proto.CompactTextString(m) }
 func (*PrepareTransactionRequest) ProtoMessage()    {}
 func (*PrepareTransactionRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_b8b9452d77b1c7d2, []int{2}
+	return fileDescriptor_b8b9452d77b1c7d2, []int{8}
 }
 
 func (m *PrepareTransactionRequest) XXX_Unmarshal(b []byte) error {
@@ -193,7 +698,7 @@ func (m *PrepareTransactionReply) Reset()         { *m = PrepareTransactionReply
 func (m *PrepareTransactionReply) String() string { return proto.CompactTextString(m) }
 func (*PrepareTransactionReply) ProtoMessage()    {}
 func (*PrepareTransactionReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_b8b9452d77b1c7d2, []int{3}
+	return fileDescriptor_b8b9452d77b1c7d2, []int{9}
 }
 
 func (m *PrepareTransactionReply) XXX_Unmarshal(b []byte) error {
@@ -240,7 +745,7 @@ func (m *UpdateAssignmentRequest) Reset()         { *m = UpdateAssignmentRequest
 func (m *UpdateAssignmentRequest) String() string { return proto.CompactTextString(m) }
 func (*UpdateAssignmentRequest) ProtoMessage()    {}
 func (*UpdateAssignmentRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_b8b9452d77b1c7d2, []int{4}
+	return fileDescriptor_b8b9452d77b1c7d2, []int{10}
 }
 
 func (m *UpdateAssignmentRequest) XXX_Unmarshal(b []byte) error {
@@ -286,7 +791,7 @@ func (m *UpdateAssignmentReply) Reset()         { *m = UpdateAssignmentReply{} }
 func (m *UpdateAssignmentReply) String() string { return proto.CompactTextString(m) }
 func (*UpdateAssignmentReply) ProtoMessage()    {}
 func (*UpdateAssignmentReply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_b8b9452d77b1c7d2, []int{5}
+	return fileDescriptor_b8b9452d77b1c7d2, []int{11}
 }
 
 func (m *UpdateAssignmentReply) XXX_Unmarshal(b []byte) error {
@@ -315,8 +820,15 @@ func (m *UpdateAssignmentReply) GetSuccess() bool {
 }
 
 func init() {
+	proto.RegisterEnum("twist.TransactionEventType", TransactionEventType_name, TransactionEventType_value)
 	proto.RegisterType((*TransactionRequest)(nil), "twist.TransactionRequest")
 	proto.RegisterType((*TransactionEvent)(nil), "twist.TransactionEvent")
+	proto.RegisterType((*TaskResults)(nil), "twist.TaskResults")
+	proto.RegisterType((*TaskResult)(nil), "twist.TaskResult")
+	proto.RegisterType((*FailureReason)(nil), "twist.FailureReason")
+	proto.RegisterType((*RunnerInfo)(nil), "twist.RunnerInfo")
+	proto.RegisterType((*CommandReceipt)(nil), "twist.CommandReceipt")
+	proto.RegisterType((*TransactionState)(nil), "twist.TransactionState")
 	proto.RegisterType((*PrepareTransactionRequest)(nil), "twist.PrepareTransactionRequest")
 	proto.RegisterType((*PrepareTransactionReply)(nil), "twist.PrepareTransactionReply")
 	proto.RegisterType((*UpdateAssignmentRequest)(nil), "twist.UpdateAssignmentRequest")
@@ -326,26 +838,62 @@ func init() {
 func init() { proto.RegisterFile("supervisor.proto", fileDescriptor_b8b9452d77b1c7d2) }
 
 var fileDescriptor_b8b9452d77b1c7d2 = []byte{
-	// 299 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x92, 0xc1, 0x4a, 0xfb, 0x40,
-	0x10, 0xc6, 0xff, 0xe9, 0xbf, 0x6a, 0x3a, 0x20, 0x84, 0x01, 0x69, 0x0c, 0x45, 0xca, 0xe2, 0xc1,
-	0x53, 0x40, 0x7d, 0x02, 0xa1, 0x1e, 0x7a, 0x29, 0x12, 0x2d, 0x28, 0x9e, 0xd6, 0x64, 0x90, 0x40,
-	0xb2, 0xbb, 0xee, 0x6e, 0x2a, 0x79, 0x07, 0x9f, 0xc8, 0xa7, 0x93, 0x2e, 0xa6, 0xd5, 0xa6, 0x29,
-	0x0a, 0xde, 0xf2, 0xcd, 0x97, 0xfd, 0xcd, 0x37, 0xc3, 0x40, 0x60, 0x2a, 0x45, 0x7a, 0x91, 0x1b,
-	0xa9, 0x63, 0xa5, 0xa5, 0x95, 0xb8, 0x67, 0x5f, 0x73, 0x63, 0xd9, 0x0c, 0xf0, 0x4e, 0x73, 0x61,
-	0x78, 0x6a, 0x73, 0x29, 0x12, 0x7a, 0xa9, 0xc8, 0x58, 0x3c, 0x85, 0x43, 0xbb, 0xae, 0x4e, 0x27,
-	0xa1, 0x37, 0xf6, 0xce, 0x06, 0xc9, 0xf7, 0x22, 0x22, 0xf4, 0x4b, 0x99, 0x51, 0xd8, 0x73, 0xa6,
-	0xfb, 0x66, 0x6f, 0x1e, 0x04, 0x5f, 0x80, 0xd7, 0x0b, 0x12, 0x3f, 0xc5, 0x45, 0xe0, 0x27, 0x95,
-	0x10, 0xa4, 0xa7, 0x93, 0x4f, 0xe4, 0x4a, 0xe3, 0x08, 0x06, 0xb4, 0x44, 0xcd, 0x78, 0x49, 0xe1,
-	0x7f, 0x67, 0xae, 0x0b, 0x18, 0xc2, 0x81, 0xe2, 0x75, 0x21, 0x79, 0x16, 0xf6, 0x9d, 0xd7, 0x48,
-	0x36, 0x87, 0xe3, 0x1b, 0x4d, 0x8a, 0x6b, 0xfa, 0xd3, 0x29, 0x1f, 0x60, 0xb8, 0x0d, 0xab, 0x8a,
-	0x7a, 0x99, 0xc5, 0x54, 0x69, 0x4a, 0xc6, 0x38, 0x9c, 0x9f, 0x34, 0xb2, 0xdd, 0xae, 0xb7, 0xa5,
-	0x1d, 0x7b, 0x84, 0xe1, 0x5c, 0x65, 0xdc, 0xd2, 0x95, 0x31, 0xf9, 0xb3, 0x28, 0x49, 0xd8, 0xdf,
-	0xe5, 0x8d, 0xc0, 0xd7, 0x1b, 0x6b, 0x6c, 0x34, 0x3b, 0x87, 0xa3, 0x36, 0x7c, 0x67, 0xea, 0x8b,
-	0x77, 0x0f, 0xe0, 0x76, 0x75, 0x3c, 0x78, 0x0f, 0xd8, 0x9e, 0x1c, 0xc7, 0xb1, 0xbb, 0xa6, 0xb8,
-	0x73, 0xd7, 0xd1, 0xc9, 0x8e, 0x3f, 0x54, 0x51, 0xb3, 0x7f, 0x98, 0x40, 0xb0, 0x99, 0x0d, 0x9b,
-	0x57, 0x1d, 0x1b, 0x89, 0x46, 0x9d, 0xbe, 0x63, 0x3e, 0xed, 0xbb, 0x5b, 0xbf, 0xfc, 0x08, 0x00,
-	0x00, 0xff, 0xff, 0xd6, 0xf0, 0x05, 0xb7, 0xff, 0x02, 0x00, 0x00,
+	// 873 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x55, 0x4d, 0x6f, 0xdb, 0x46,
+	0x13, 0x16, 0xad, 0xef, 0xd1, 0x6b, 0x67, 0x3d, 0xaf, 0x6c, 0x33, 0xaa, 0x11, 0x08, 0x44, 0x0f,
+	0x46, 0x03, 0x38, 0x8d, 0x0b, 0x14, 0x45, 0xd1, 0x1e, 0x14, 0x72, 0x1d, 0xb1, 0x96, 0xc8, 0x60,
+	0x49, 0xa5, 0x29, 0x7a, 0x10, 0x58, 0x69, 0x93, 0x0a, 0x95, 0x48, 0x96, 0x4b, 0xa5, 0xd0, 0x3d,
+	0xc7, 0xfe, 0x86, 0xfe, 0x90, 0xfe, 0xba, 0x62, 0xf9, 0x21, 0x8a, 0x92, 0x1d, 0xa4, 0x40, 0x6f,
+	0x9c, 0x99, 0xe7, 0x99, 0xdd, 0x99, 0x79, 0x66, 0x09, 0x44, 0xac, 0x43, 0x1e, 0xbd, 0x5f, 0x88,
+	0x20, 0xba, 0x0e, 0xa3, 0x20, 0x0e, 0xb0, 0x1e, 0xff, 0xb1, 0x10, 0x71, 0xef, 0xd1, 0x2c, 0x58,
+	0xad, 0x3c, 0x7f, 0xce, 0x33, 0xbf, 0x66, 0x01, 0xba, 0x91, 0xe7, 0x0b, 0x6f, 0x16, 0x2f, 0x02,
+	0x9f, 0xf1, 0xdf, 0xd7, 0x5c, 0xc4, 0xf8, 0x39, 0x1c, 0xc7, 0x85, 0xd7, 0x34, 0x54, 0xa5, 0xaf,
+	0x5c, 0xb5, 0x59, 0xd9, 0x89, 0x08, 0xb5, 0x55, 0x30, 0xe7, 0xea, 0x51, 0x12, 0x4c, 0xbe, 0xb5,
+	0xbf, 0x6a, 0x40, 0x76, 0x12, 0xd2, 0xf7, 0xdc, 0xff, 0xd4, 0x74, 0x3d, 0x68, 0xb1, 0xb5, 0xef,
+	0xf3, 0xc8, 0x34, 0xb2, 0x94, 0x5b, 0x1b, 0x2f, 0xa1, 0xcd, 0x65, 0x2a, 0xcb, 0x5b, 0x71, 0xb5,
+	0x9a, 0x04, 0x0b, 0x07, 0xaa, 0xd0, 0x0c, 0xbd, 0xcd, 0x32, 0xf0, 0xe6, 0x6a, 0x2d, 0x89, 0xe5,
+	0xa6, 0xcc, 0x29, 0x64, 0x4d, 0xfe, 0x8c, 0xab, 0xf5, 0xbe, 0x72, 0x55, 0x63, 0x5b, 0x1b, 0x9f,
+	0x41, 0x2d, 0xde, 0x84, 0x5c, 0x6d, 0xf4, 0x95, 0xab, 0x93, 0x9b, 0xcf, 0xae, 0x93, 0x0e, 0x5d,
+	0xef, 0x5f, 0xde, 0xdd, 0x84, 0x9c, 0x25, 0x40, 0xfc, 0x1a, 0x3a, 0xb1, 0x27, 0x7e, 0x63, 0x5c,
+	0xac, 0x97, 0xb1, 0x50, 0x9b, 0x7d, 0xe5, 0xaa, 0x73, 0x83, 0x39, 0xaf, 0x88, 0x0c, 0x2b, 0x6c,
+	0x17, 0x88, 0x5f, 0x42, 0xf3, 0xad, 0xb7, 0x58, 0xae, 0x23, 0xae, 0xb6, 0x12, 0x4e, 0x37, 0xe3,
+	0xdc, 0xa6, 0x5e, 0xc6, 0x3d, 0x11, 0xf8, 0xc3, 0x0a, 0xcb, 0x61, 0xf8, 0x14, 0x1a, 0x51, 0x52,
+	0xba, 0xda, 0x4e, 0x08, 0xa7, 0x19, 0x21, 0xeb, 0x87, 0xff, 0x36, 0x18, 0x56, 0x58, 0x06, 0xc1,
+	0x6f, 0xa0, 0x25, 0x4f, 0x1b, 0x2d, 0x44, 0xac, 0x42, 0x02, 0xef, 0x1d, 0xd6, 0xe2, 0x66, 0x88,
+	0x61, 0x85, 0x6d, 0xd1, 0xf8, 0x0c, 0xea, 0x22, 0xf6, 0x62, 0xae, 0x9e, 0x26, 0xb4, 0x8b, 0x43,
+	0x9a, 0x23, 0xc3, 0xc3, 0x0a, 0x4b, 0x71, 0xf8, 0x1c, 0x9a, 0x11, 0x9f, 0xf1, 0x45, 0x18, 0xab,
+	0x98, 0x50, 0xce, 0x32, 0x8a, 0x9e, 0xca, 0x8a, 0xa5, 0x41, 0x59, 0x4a, 0x86, 0x7b, 0xd1, 0x82,
+	0xc6, 0x9c, 0xc7, 0xde, 0x62, 0xf9, 0x43, 0xad, 0xd5, 0x21, 0xa7, 0xda, 0xb7, 0xd0, 0xd9, 0x69,
+	0x15, 0x3e, 0x95, 0x19, 0xd3, 0x7e, 0x2a, 0xfd, 0xea, 0x4e, 0xa9, 0x05, 0x88, 0xe5, 0x08, 0xed,
+	0x4f, 0x05, 0xa0, 0xf0, 0xe3, 0x39, 0x34, 0x64, 0x29, 0x5b, 0x3d, 0x65, 0x96, 0xf4, 0xa7, 0xb7,
+	0xcf, 0x64, 0x94, 0x59, 0x52, 0x26, 0x62, 0x3d, 0x9b, 0x71, 0x21, 0x12, 0x09, 0xb5, 0x58, 0x6e,
+	0xe2, 0x13, 0x00, 0x59, 0xe0, 0x5a, 0xe8, 0x52, 0xcf, 0x52, 0x43, 0x75, 0xb6, 0xe3, 0xc1, 0x2e,
+	0xd4, 0x79, 0x14, 0x05, 0x51, 0xa2, 0xa1, 0x36, 0x4b, 0x0d, 0xed, 0x7b, 0x38, 0x2e, 0x4d, 0x50,
+	0x2e, 0xc4, 0x4c, 0x26, 0x48, 0xaf, 0x93, 0x7c, 0xcb, 0x43, 0x57, 0x5c, 0x08, 0xef, 0x5d, 0xbe,
+	0x27, 0xb9, 0xa9, 0x7d, 0x07, 0x50, 0xcc, 0x53, 0x2a, 0x35, 0xca, 0xd5, 0x9f, 0xf2, 0xb7, 0xb6,
+	0xcc, 0xfb, 0x6b, 0x20, 0xe2, 0x7c, 0xd1, 0xe4, 0xb7, 0x76, 0x0d, 0x27, 0xe5, 0xa6, 0xcb, 0x1d,
+	0xc9, 0xb6, 0x7b, 0x9b, 0xa2, 0x70, 0x68, 0x1f, 0x14, 0x20, 0xfb, 0x83, 0xfd, 0xc4, 0xc5, 0xec,
+	0xe6, 0x32, 0x49, 0xcf, 0x4f, 0x8d, 0xd2, 0x6a, 0x55, 0xf7, 0x56, 0xeb, 0xc1, 0x85, 0xd4, 0x26,
+	0xf0, 0xf8, 0x55, 0xc4, 0x43, 0x2f, 0xe2, 0xff, 0xe9, 0xb3, 0xf3, 0x13, 0x5c, 0xdc, 0x97, 0x36,
+	0x5c, 0x6e, 0x76, 0xa7, 0xae, 0x94, 0xa7, 0x7e, 0x70, 0xdc, 0xd1, 0x3d, 0xc7, 0x69, 0x3f, 0xc3,
+	0xc5, 0x24, 0x9c, 0x7b, 0x31, 0x1f, 0x08, 0xb1, 0x78, 0xe7, 0xaf, 0xb8, 0x1f, 0xff, 0xbb, 0xfb,
+	0xee, 0x4e, 0xf6, 0xa8, 0x3c, 0x59, 0xed, 0x39, 0x9c, 0x1d, 0x26, 0xff, 0xe8, 0xad, 0xbf, 0xf8,
+	0x50, 0x85, 0xee, 0x7d, 0x8f, 0x14, 0x9e, 0xc2, 0x31, 0x7d, 0x4d, 0x2d, 0x77, 0x3a, 0xb1, 0xee,
+	0x2c, 0xfb, 0x47, 0x8b, 0x54, 0x10, 0xe1, 0x24, 0x75, 0x0d, 0x1c, 0xc7, 0x7c, 0x69, 0x51, 0x83,
+	0x28, 0xd8, 0x83, 0xf3, 0xd4, 0xe7, 0x0e, 0x9c, 0x3b, 0x67, 0xca, 0xe8, 0x4b, 0xd3, 0x71, 0x29,
+	0xa3, 0x06, 0x39, 0xc2, 0x73, 0xc0, 0xdd, 0xd8, 0x48, 0x46, 0x0c, 0x52, 0x45, 0x15, 0xba, 0x65,
+	0xce, 0xab, 0xd1, 0x40, 0xa7, 0x06, 0xa9, 0x95, 0x19, 0x53, 0x46, 0xc7, 0xf6, 0x6b, 0x6a, 0x90,
+	0x7a, 0x99, 0x31, 0xb5, 0x6c, 0x77, 0x7a, 0x6b, 0x4f, 0x2c, 0x83, 0x34, 0xf0, 0xff, 0xf0, 0x28,
+	0x8d, 0xe8, 0xb6, 0x75, 0x6b, 0xb2, 0x31, 0x35, 0x48, 0xb3, 0xb8, 0xa8, 0x3e, 0xb0, 0x74, 0x3a,
+	0xa2, 0x06, 0x69, 0x15, 0xf5, 0xb8, 0xe6, 0x98, 0xda, 0x13, 0x97, 0xb4, 0xf1, 0x31, 0x9c, 0xa5,
+	0xae, 0x21, 0x9d, 0x30, 0xd3, 0x71, 0x4d, 0x7d, 0x3a, 0x36, 0xdf, 0x50, 0x83, 0x40, 0x51, 0x56,
+	0x11, 0xd2, 0xed, 0xf1, 0xd8, 0x74, 0x49, 0x07, 0x2f, 0x41, 0xdd, 0x8f, 0x31, 0x7b, 0x34, 0x7a,
+	0x31, 0xd0, 0xef, 0xc8, 0xff, 0x0a, 0xa6, 0xc4, 0x0f, 0x2c, 0x63, 0xca, 0xa8, 0x4e, 0x4d, 0x59,
+	0xc6, 0x71, 0x51, 0x86, 0xe3, 0x0e, 0x5c, 0x2a, 0x0b, 0xb7, 0x99, 0x6c, 0xc9, 0xc9, 0xcd, 0xdf,
+	0x0a, 0x80, 0xb3, 0xfd, 0xcb, 0xe2, 0x1b, 0xc0, 0x43, 0x01, 0x62, 0x3f, 0x7b, 0xcc, 0x1e, 0x94,
+	0x7c, 0xef, 0xc9, 0x47, 0x10, 0xe1, 0x72, 0xa3, 0x55, 0x90, 0x01, 0xd9, 0x97, 0x08, 0xe6, 0xac,
+	0x07, 0x84, 0xd9, 0xbb, 0x7c, 0x30, 0x9e, 0xe4, 0xfc, 0xa5, 0x91, 0xfc, 0xfc, 0xbf, 0xfa, 0x67,
+	0x00, 0x81, 0xef, 0xee, 0x08, 0x28, 0x08, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
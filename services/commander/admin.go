@@ -0,0 +1,108 @@
+package commander
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	app "twist-commander/app/interface"
+	"twist-commander/app/store"
+	pb "twist-commander/pb"
+)
+
+// AuthorizeAdmin makes sure caller provides admin token in "authorization"
+// metadata. Admin APIs are disabled if no token was configured.
+func AuthorizeAdmin(ctx context.Context, token string) error {
+
+	if token == "" {
+		return status.Error(codes.PermissionDenied, "Admin API is disabled")
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "Admin token is required")
+	}
+
+	for _, value := range md.Get("authorization") {
+		value = strings.TrimPrefix(value, "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(value), []byte(token)) == 1 {
+			return nil
+		}
+	}
+
+	return status.Error(codes.PermissionDenied, "Invalid admin token")
+}
+
+func (service *Service) ForceResolve(ctx context.Context, in *pb.ForceResolveRequest) (*pb.ForceResolveReply, error) {
+
+	err := AuthorizeAdmin(ctx, service.app.GetAdminToken())
+	if err != nil {
+		return nil, err
+	}
+
+	if in.TransactionID == "" {
+		return nil, status.Error(codes.InvalidArgument, "Transaction ID is required")
+	}
+
+	// Outcome is matched regardless of case, so both "canceled" and the
+	// outcome name "Canceled" are accepted
+	var outcome string
+	switch {
+	case strings.EqualFold(in.Outcome, OutcomeConfirmed):
+		outcome = OutcomeConfirmed
+	case strings.EqualFold(in.Outcome, OutcomeCanceled):
+		outcome = OutcomeCanceled
+	default:
+		return nil, status.Error(codes.InvalidArgument, "Outcome must be \"confirmed\" or \"canceled\"")
+	}
+
+	// Runner always receives lowercase outcome
+	in.Outcome = strings.ToLower(outcome)
+
+	if in.RedriveCancel && outcome != OutcomeCanceled {
+		return nil, status.Error(codes.InvalidArgument, "Cancel actions can only be re-driven for canceled transaction")
+	}
+
+	if in.Operator == "" || in.Reason == "" {
+		return nil, status.Error(codes.InvalidArgument, "Operator and reason are required")
+	}
+
+	// Audit record
+	log.WithFields(log.Fields{
+		"transaction":   in.TransactionID,
+		"outcome":       in.Outcome,
+		"redriveCancel": in.RedriveCancel,
+		"operator":      in.Operator,
+		"reason":        in.Reason,
+	}).Warn("Force resolving transaction")
+
+	err = service.commander.ForceResolve(in.TransactionID, in)
+	if err != nil {
+
+		// Signal server is not available, or transaction ID is invalid
+		if isStatusError(err) {
+			return nil, err
+		}
+
+		log.Error(err)
+		return &pb.ForceResolveReply{
+			Success:       false,
+			TransactionID: in.TransactionID,
+		}, nil
+	}
+
+	service.record(in.TransactionID, func(tx *app.Transaction) {
+		tx.Outcome = outcome
+		store.ApplyTransition(tx, store.StateResolved, in.Operator+": "+in.Reason)
+	})
+
+	return &pb.ForceResolveReply{
+		Success:       true,
+		TransactionID: in.TransactionID,
+	}, nil
+}
@@ -1,21 +1,84 @@
 package commander
 
 import (
+	"context"
 	"errors"
+	"sync"
+	"time"
 	app "twist-commander/app/interface"
 	pb "twist-commander/pb"
 
-	"github.com/gogo/protobuf/proto"
 	"github.com/golang/protobuf/ptypes/any"
-	"github.com/nats-io/nats.go"
+	uuid "github.com/satori/go.uuid"
 	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// Command modes
+const (
+
+	// Command is published and events are watched on a separate subject
+	CommandModePublish = "publish"
+
+	// Command is sent as request and runner replies with the final event
+	CommandModeRequest = "request"
+)
+
+var errAgentClosed = errors.New("Agent was closed")
+
+// RetransmitPolicy controls resending of commands which were not acknowledged
+// by runner
+type RetransmitPolicy struct {
+
+	// Zero disables retransmission, for runners which do not acknowledge
+	AckTimeout  time.Duration
+	MaxAttempts int
+	MaxBackoff  time.Duration
+}
+
 type Agent struct {
 	app           app.AppImpl
 	TransactionID string
-	Subscriber    *nats.Subscription
+	Subscriber    app.Subscription
 	EventChannel  chan *pb.TransactionEvent
+	closeOnce     sync.Once
+	onClose       func()
+	done          chan struct{}
+	retransmit    RetransmitPolicy
+	mode          string
+
+	// How long to wait for reply in request mode
+	requestTimeout time.Duration
+
+	// Runners are asked to emit events to subject of this instance
+	instanceReplies bool
+	replySubscriber app.Subscription
+
+	mutex   sync.Mutex
+	closed  bool
+	pending *pendingCommand
+	err     error
+
+	// Sequence of the last event which was delivered
+	lastSequence uint64
+	sequences    *SequenceTracker
+
+	// State is queried once events were missed
+	stateTimeout time.Duration
+	stateQuery   *stateQuery
+}
+
+// stateQuery waits for state which is newer than events delivered before the
+// gap
+type stateQuery struct {
+	after    uint64
+	reported chan struct{}
+}
+
+type pendingCommand struct {
+	id    string
+	acked chan struct{}
 }
 
 func CreateAgent(a app.AppImpl, transactionID string) *Agent {
@@ -23,63 +86,662 @@ func CreateAgent(a app.AppImpl, transactionID string) *Agent {
 		app:           a,
 		TransactionID: transactionID,
 		EventChannel:  make(chan *pb.TransactionEvent),
+		done:          make(chan struct{}),
 	}
 }
 
 func (agent *Agent) OpenEventChannel() error {
 
+	topic, err := agent.app.GetSubjects().TransactionEvents(agent.TransactionID)
+	if err != nil {
+		return err
+	}
+
+	topics := []string{topic}
+
+	// Events which are not caused by commands, and events of runners which
+	// ignore reply subject, still come to the shared subject
+	if agent.instanceReplies {
+		topic, err = agent.replySubject()
+		if err != nil {
+			return err
+		}
+
+		topics = append(topics, topic)
+	}
+
 	// Listening to queue
 	sb := agent.app.GetSignalBus()
-	sub, err := sb.Watch("twist.transaction."+agent.TransactionID+".eventEmitted", func(msg *nats.Msg) {
-
-		var event pb.TransactionEvent
-		err := proto.Unmarshal(msg.Data, &event)
+	subs := make([]app.Subscription, 0, len(topics))
+	for _, topic := range topics {
+		sub, err := sb.Watch(topic, "agent:"+agent.TransactionID, agent.handleMessage)
 		if err != nil {
-			return
+			log.Error("did not connect: ", err)
+			agent.unwatch(subs...)
+			return errors.New("Failed to connect to signal server")
 		}
 
-		defer func() {
-			if recover() != nil {
-				// Do nothing
-			}
-		}()
+		subs = append(subs, sub)
+	}
 
-		agent.EventChannel <- &event
-	})
-	if err != nil {
-		log.Error("did not connect: ", err)
-		return errors.New("Failed to connect to signal server")
+	// Channel might be opened while agent is being closed, when request falls
+	// back to publishing
+	agent.mutex.Lock()
+	closed := agent.closed
+	if !closed {
+		agent.Subscriber = subs[0]
+		if len(subs) > 1 {
+			agent.replySubscriber = subs[1]
+		}
 	}
+	agent.mutex.Unlock()
 
-	agent.Subscriber = sub
+	if closed {
+		agent.unwatch(subs...)
+		return errAgentClosed
+	}
 
 	return nil
 }
 
+func (agent *Agent) unwatch(subs ...app.Subscription) {
+	for _, sub := range subs {
+		if sub != nil {
+			agent.app.GetSignalBus().Unwatch(sub)
+		}
+	}
+}
+
+func (agent *Agent) replySubject() (string, error) {
+	return agent.app.GetSubjects().InstanceEvents(agent.app.GetInstanceID(), agent.TransactionID)
+}
+
+func (agent *Agent) handleMessage(msg *app.Message) {
+
+	var event pb.TransactionEvent
+	err := agent.app.GetCodec().Decode(msg.Data, &event)
+	if err != nil {
+		return
+	}
+
+	normalizeEvent(&event)
+
+	// Acknowledgement is handled by agent itself
+	if event.Type == pb.TransactionEventType_EVENT_COMMAND_RECEIVED {
+		agent.acknowledge(event.GetReceipt().GetCommandID())
+		return
+	}
+
+	// Reply to "queryState" command
+	if event.Type == pb.TransactionEventType_EVENT_STATE_REPORTED {
+		agent.restoreState(&event)
+		return
+	}
+
+	if !agent.checkSequence(&event) {
+		return
+	}
+
+	agent.deliver(&event)
+}
+
+// CloseEventChannel can be called more than once, because agent might be closed
+// by shutdown while its owner is still waiting for events
 func (agent *Agent) CloseEventChannel() {
-	agent.Subscriber.Unsubscribe()
-	close(agent.EventChannel)
+	agent.closeOnce.Do(func() {
+		agent.mutex.Lock()
+		agent.closed = true
+		subs := []app.Subscription{agent.Subscriber, agent.replySubscriber}
+		agent.mutex.Unlock()
+
+		agent.unwatch(subs...)
+
+		close(agent.done)
+		close(agent.EventChannel)
+
+		if agent.onClose != nil {
+			agent.onClose()
+		}
+	})
 }
 
-func (agent *Agent) SendCommand(command string, payload *any.Any) error {
+func (agent *Agent) deliver(event *pb.TransactionEvent) {
+
+	defer func() {
+		if recover() != nil {
+			// Do nothing
+		}
+	}()
+
+	agent.EventChannel <- event
+}
+
+// checkSequence drops duplicate events, and queries state of transaction from
+// runner when some events were missed
+func (agent *Agent) checkSequence(event *pb.TransactionEvent) bool {
+
+	// Event is not sequenced
+	if event.Sequence == 0 {
+		return true
+	}
+
+	agent.mutex.Lock()
+	last := agent.lastSequence
+	if event.Sequence > last {
+		agent.lastSequence = event.Sequence
+	}
+	agent.mutex.Unlock()
+
+	// Agent starts from the last event seen by earlier requests of transaction,
+	// while concurrent requests receive the same events and check them on their
+	// own
+	if event.Sequence <= last {
+		log.WithFields(log.Fields{
+			"transactionID": agent.TransactionID,
+			"eventName":     event.EventName,
+			"sequence":      event.Sequence,
+			"lastSequence":  last,
+		}).Warn("Dropped duplicate event")
+
+		return false
+	}
+
+	if agent.sequences != nil {
+		agent.sequences.Observe(agent.TransactionID, event.Sequence)
+	}
+
+	// Agent joins in the middle of transaction, first event is the baseline
+	if last == 0 {
+		return true
+	}
+
+	// Events caused by other instances go to their own subjects, so sequence
+	// has gaps anyway
+	if agent.instanceReplies {
+		return true
+	}
+
+	if event.Sequence > last+1 {
+		log.WithFields(log.Fields{
+			"transactionID": agent.TransactionID,
+			"eventName":     event.EventName,
+			"sequence":      event.Sequence,
+			"expected":      last + 1,
+		}).Warn("Missed events of transaction, querying state")
+
+		go agent.queryState(last)
+	}
+
+	return true
+}
+
+// queryState asks runner for the current state, which comes back as
+// "StateReported" event. Request fails unless runner reports it in time.
+func (agent *Agent) queryState(after uint64) {
+
+	agent.mutex.Lock()
+	if agent.stateQuery != nil {
+		agent.mutex.Unlock()
+		return
+	}
+
+	reported := make(chan struct{})
+	agent.stateQuery = &stateQuery{
+		after:    after,
+		reported: reported,
+	}
+	agent.mutex.Unlock()
+
+	err := agent.emitCommand(agent.createCommand("queryState", nil))
+	if err != nil {
+		log.WithFields(log.Fields{
+			"transactionID": agent.TransactionID,
+			"error":         err,
+		}).Error("Failed to query state of transaction")
+
+		agent.fail(status.Error(codes.Unavailable, "Failed to query state of transaction"))
+		return
+	}
+
+	timer := time.NewTimer(agent.stateTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-reported:
+	case <-agent.done:
+	case <-timer.C:
+		log.WithFields(log.Fields{
+			"transactionID": agent.TransactionID,
+			"timeout":       agent.stateTimeout,
+		}).Error("Runner did not report state of transaction")
+
+		agent.fail(status.Error(codes.DeadlineExceeded, "Runner did not report state of transaction"))
+	}
+}
+
+// restoreState delivers reported state as the event which was missed, so that
+// owner stops waiting if transaction is finished already. Every agent of
+// transaction receives the state, which is only taken by agents which queried
+// it, and only if it is newer than events delivered before the gap.
+func (agent *Agent) restoreState(event *pb.TransactionEvent) {
+
+	state := event.GetState()
+	if state == nil {
+		log.WithFields(log.Fields{
+			"transactionID": agent.TransactionID,
+		}).Error("State of transaction is missing")
+
+		return
+	}
+
+	agent.mutex.Lock()
+	query := agent.stateQuery
+
+	// Older state answers query of someone else
+	if query == nil || state.Sequence <= query.after {
+		last := agent.lastSequence
+		agent.mutex.Unlock()
+
+		log.WithFields(log.Fields{
+			"transactionID": agent.TransactionID,
+			"sequence":      state.Sequence,
+			"lastSequence":  last,
+		}).Debug("Dropped state which was not queried")
+
+		return
+	}
+
+	close(query.reported)
+	agent.stateQuery = nil
+	if state.Sequence > agent.lastSequence {
+		agent.lastSequence = state.Sequence
+	}
+	agent.mutex.Unlock()
+
+	if agent.sequences != nil {
+		agent.sequences.Observe(agent.TransactionID, state.Sequence)
+	}
+
+	log.WithFields(log.Fields{
+		"transactionID": agent.TransactionID,
+		"state":         state.State,
+		"sequence":      state.Sequence,
+	}).Info("Restored state of transaction")
+
+	restored := &pb.TransactionEvent{
+		TransactionID: agent.TransactionID,
+		RunnerID:      event.RunnerID,
+		EventName:     state.State,
+		Payload:       state.Payload,
+		Sequence:      state.Sequence,
+	}
+
+	normalizeEvent(restored)
+
+	agent.deliver(restored)
+}
+
+// Err returns the reason why agent was closed before its owner closed it
+func (agent *Agent) Err() error {
+
+	agent.mutex.Lock()
+	defer agent.mutex.Unlock()
+
+	return agent.err
+}
+
+func (agent *Agent) fail(err error) {
+
+	agent.mutex.Lock()
+	agent.err = err
+	agent.mutex.Unlock()
+
+	agent.CloseEventChannel()
+}
+
+// createCommand stamps command with ID of this instance, so that runner can
+// route events back to it
+func (agent *Agent) createCommand(command string, payload *any.Any) *pb.TransactionCommand {
 
-	// Preparing transaction command
 	cmd := &pb.TransactionCommand{
 		TransactionID: agent.TransactionID,
 		Command:       command,
 		Payload:       payload,
+		CommandID:     uuid.NewV4().String(),
+		Attempt:       1,
+		InstanceID:    agent.app.GetInstanceID(),
+	}
+
+	// Reply of request comes to inbox of request anyway
+	if agent.instanceReplies && agent.mode != CommandModeRequest {
+		topic, err := agent.replySubject()
+		if err == nil {
+			cmd.ReplySubject = topic
+		}
+	}
+
+	return cmd
+}
+
+func (agent *Agent) SendCommand(command string, payload *any.Any) error {
+
+	// Preparing transaction command
+	cmd := agent.createCommand(command, payload)
+
+	if agent.mode == CommandModeRequest {
+		return agent.requestCommand(cmd)
+	}
+
+	return agent.publishCommand(cmd)
+}
+
+// publishCommand emits command, and retransmits it until runner acknowledges
+// if it is enabled
+func (agent *Agent) publishCommand(cmd *pb.TransactionCommand) error {
+
+	if agent.retransmit.AckTimeout <= 0 {
+		return agent.emitCommand(cmd)
+	}
+
+	// Runner might acknowledge before emitting returns
+	pending := &pendingCommand{
+		id:    cmd.CommandID,
+		acked: make(chan struct{}),
+	}
+
+	agent.mutex.Lock()
+	agent.pending = pending
+	agent.mutex.Unlock()
+
+	err := agent.emitCommand(cmd)
+	if err != nil {
+		agent.mutex.Lock()
+		if agent.pending == pending {
+			agent.pending = nil
+		}
+		agent.mutex.Unlock()
+
+		return err
+	}
+
+	go agent.retransmitCommand(cmd, pending)
+
+	return nil
+}
+
+func (agent *Agent) acknowledge(commandID string) {
+
+	agent.mutex.Lock()
+	defer agent.mutex.Unlock()
+
+	if agent.pending == nil || agent.pending.id != commandID {
+		return
+	}
+
+	close(agent.pending.acked)
+	agent.pending = nil
+}
+
+// retransmitCommand resends command with backoff until runner acknowledges it.
+// Runner which never acknowledges is considered dead, while acknowledged
+// command only means that runner is slow.
+func (agent *Agent) retransmitCommand(cmd *pb.TransactionCommand, pending *pendingCommand) {
+
+	wait := agent.retransmit.AckTimeout
+
+	for {
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-pending.acked:
+			timer.Stop()
+			return
+		case <-agent.done:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if int(cmd.Attempt) >= agent.retransmit.MaxAttempts {
+			break
+		}
+
+		cmd.Attempt++
+
+		log.WithFields(log.Fields{
+			"transactionID": cmd.TransactionID,
+			"command":       cmd.Command,
+			"commandID":     cmd.CommandID,
+			"attempt":       cmd.Attempt,
+		}).Warn("Command was not acknowledged, retransmitting")
+
+		err := agent.emitCommand(cmd)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"commandID": cmd.CommandID,
+				"error":     err,
+			}).Warn("Failed to retransmit command")
+		}
+
+		wait *= 2
+		if agent.retransmit.MaxBackoff > 0 && wait > agent.retransmit.MaxBackoff {
+			wait = agent.retransmit.MaxBackoff
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"transactionID": cmd.TransactionID,
+		"command":       cmd.Command,
+		"commandID":     cmd.CommandID,
+		"attempts":      cmd.Attempt,
+	}).Error("Runner did not acknowledge command")
+
+	agent.fail(status.Error(codes.Unavailable, "Runner did not acknowledge command"))
+}
+
+// requestCommand sends command to runner which replies directly to this
+// instance, reply is delivered as the only event of agent. Command is
+// published instead if runner neither acknowledges nor replies in time, since
+// it might only listen to published commands.
+func (agent *Agent) requestCommand(cmd *pb.TransactionCommand) error {
+
+	go func() {
+		event, err := agent.awaitReply(cmd)
+		if err != nil {
+
+			// Owner is not waiting anymore
+			if status.Code(err) == codes.Canceled {
+				return
+			}
+
+			if status.Code(err) == codes.DeadlineExceeded {
+				err = agent.fallbackToPublish(cmd)
+				if err == nil {
+					return
+				}
+			}
+
+			agent.fail(err)
+			return
+		}
+
+		agent.deliver(event)
+		agent.CloseEventChannel()
+	}()
+
+	return nil
+}
+
+// awaitReply sends command as request and waits for reply of runner. Runner
+// which acknowledged the request with "CommandReceived" event is slow rather
+// than unreachable, so reply is awaited until agent is closed then, instead of
+// failing with DeadlineExceeded once request timeout expires.
+func (agent *Agent) awaitReply(cmd *pb.TransactionCommand) (*pb.TransactionEvent, error) {
+
+	pending := &pendingCommand{
+		id:    cmd.CommandID,
+		acked: make(chan struct{}),
+	}
+
+	agent.mutex.Lock()
+	agent.pending = pending
+	agent.mutex.Unlock()
+
+	topic, err := agent.app.GetSubjects().TransactionEvents(agent.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := agent.app.GetSignalBus().Watch(topic, "agent:"+agent.TransactionID, agent.handleAck)
+	if err != nil {
+		log.Error("did not connect: ", err)
+		return nil, status.Error(codes.Unavailable, "Failed to connect to signal server")
+	}
+
+	defer agent.unwatch(sub)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	type result struct {
+		event *pb.TransactionEvent
+		err   error
+	}
+
+	results := make(chan result, 1)
+
+	go func() {
+		event, err := agent.request(ctx, cmd)
+		results <- result{event, err}
+	}()
+
+	timer := time.NewTimer(agent.requestTimeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case r := <-results:
+			return r.event, r.err
+		case <-agent.done:
+			return nil, status.Error(codes.Canceled, "Request was canceled")
+		case <-timer.C:
+		}
+
+		select {
+		case <-pending.acked:
+			log.WithFields(log.Fields{
+				"transactionID": cmd.TransactionID,
+				"command":       cmd.Command,
+			}).Warn("Runner acknowledged request but did not reply in time, waiting")
+		default:
+			log.WithFields(log.Fields{
+				"transactionID": cmd.TransactionID,
+				"command":       cmd.Command,
+			}).Error("Runner did not reply to command")
+
+			return nil, status.Error(codes.DeadlineExceeded, "Runner did not reply in time")
+		}
+	}
+}
+
+// handleAck only takes acknowledgements from events, final event of request
+// is its reply
+func (agent *Agent) handleAck(msg *app.Message) {
+
+	var event pb.TransactionEvent
+	err := agent.app.GetCodec().Decode(msg.Data, &event)
+	if err != nil {
+		return
+	}
+
+	if event.Type == pb.TransactionEventType_EVENT_COMMAND_RECEIVED {
+		agent.acknowledge(event.GetReceipt().GetCommandID())
+	}
+}
+
+// request sends command and waits for reply of runner
+func (agent *Agent) request(ctx context.Context, cmd *pb.TransactionCommand) (*pb.TransactionEvent, error) {
+
+	topic, err := agent.app.GetSubjects().TransactionRequests(agent.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := agent.app.GetCodec().Encode(cmd)
+	if err != nil {
+		return nil, errors.New("Failed to create command")
+	}
+
+	sb := agent.app.GetSignalBus()
+	res, err := sb.Request(ctx, topic, data)
+	if err != nil {
+
+		if ctx.Err() == context.Canceled {
+			return nil, status.Error(codes.Canceled, "Request was canceled")
+		}
+
+		log.WithFields(log.Fields{
+			"transactionID": cmd.TransactionID,
+			"command":       cmd.Command,
+			"error":         err,
+		}).Error("Failed to send command to runner")
+
+		return nil, status.Error(codes.Unavailable, "Failed to send command")
+	}
+
+	var event pb.TransactionEvent
+	err = agent.app.GetCodec().Decode(res, &event)
+	if err != nil {
+		return nil, errors.New("Failed to parse reply from runner")
+	}
+
+	normalizeEvent(&event)
+
+	return &event, nil
+}
+
+// fallbackToPublish publishes command which was not answered as request, and
+// events are watched like in publish mode
+func (agent *Agent) fallbackToPublish(cmd *pb.TransactionCommand) error {
+
+	log.WithFields(log.Fields{
+		"transactionID": cmd.TransactionID,
+		"command":       cmd.Command,
+	}).Warn("Publishing command which was not answered as request")
+
+	err := agent.OpenEventChannel()
+	if err != nil {
+		return err
+	}
+
+	cmd.Attempt++
+
+	return agent.publishCommand(cmd)
+}
+
+func (agent *Agent) emitCommand(cmd *pb.TransactionCommand) error {
+
+	topic, err := agent.app.GetSubjects().TransactionCommands(agent.TransactionID)
+	if err != nil {
+		return err
 	}
 
 	// Preparing command packet
-	data, err := proto.Marshal(cmd)
+	data, err := agent.app.GetCodec().Encode(cmd)
 	if err != nil {
 		return errors.New("Failed to create command")
 	}
 
 	// Send command to queue
 	sb := agent.app.GetSignalBus()
-	err = sb.Emit("twist.transaction."+agent.TransactionID+".cmdReceived", data)
+	err = sb.Emit(topic, data)
 	if err != nil {
+
+		// Signal bus is degraded, caller should fail fast
+		if status.Code(err) == codes.Unavailable {
+			return err
+		}
+
 		return errors.New("Failed to send command")
 	}
 
@@ -1,20 +1,149 @@
 package commander
 
-import app "twist-commander/app/interface"
+import (
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	app "twist-commander/app/interface"
+	"twist-commander/app/subject"
+)
 
 type AgentManager struct {
-	app app.AppImpl
+	app        app.AppImpl
+	mutex      sync.Mutex
+	agents     map[*Agent]struct{}
+	closed     bool
+	retransmit RetransmitPolicy
+
+	mode            string
+	requestTimeout  time.Duration
+	stateTimeout    time.Duration
+	sequences       *SequenceTracker
+	instanceReplies bool
 }
 
 func CreateAgentManager(a app.AppImpl) *AgentManager {
-	return &AgentManager{
-		app: a,
+
+	am := &AgentManager{
+		app:       a,
+		agents:    make(map[*Agent]struct{}),
+		sequences: CreateSequenceTracker(viper.GetDuration("command.sequence_ttl")),
+		retransmit: RetransmitPolicy{
+			AckTimeout:  viper.GetDuration("command.ack_timeout"),
+			MaxAttempts: viper.GetInt("command.max_attempts"),
+			MaxBackoff:  viper.GetDuration("command.max_backoff"),
+		},
+	}
+
+	if am.retransmit.MaxAttempts <= 0 {
+		am.retransmit.MaxAttempts = 5
+	}
+
+	am.mode = viper.GetString("command.mode")
+	if am.mode == "" {
+		am.mode = CommandModePublish
+	}
+
+	am.instanceReplies = viper.GetBool("command.instance_replies")
+
+	am.requestTimeout = viper.GetDuration("command.request_timeout")
+	if am.requestTimeout <= 0 {
+		am.requestTimeout = 30 * time.Second
 	}
+
+	am.stateTimeout = viper.GetDuration("command.state_timeout")
+	if am.stateTimeout <= 0 {
+		am.stateTimeout = 10 * time.Second
+	}
+
+	// Events will never come if signal bus is gone
+	a.GetSignalBus().NotifyUnavailable(am.abortAll)
+
+	return am
 }
 
-func (am *AgentManager) CreateAgent(transactionID string) *Agent {
+func (am *AgentManager) CreateAgent(transactionID string) (*Agent, error) {
+
+	// Transaction ID becomes a token of subjects
+	if !subject.IsValidToken(transactionID) {
+		return nil, subject.ErrInvalidTransactionID
+	}
+
+	am.mutex.Lock()
+	defer am.mutex.Unlock()
+
+	// No more agents after shutdown
+	if am.closed {
+		return nil, status.Error(codes.Unavailable, "Commander is shutting down")
+	}
+
+	// Reject new work rather than waiting for events which will never come
+	if !am.app.GetSignalBus().IsAvailable() {
+		return nil, status.Error(codes.Unavailable, "Signal server is not available")
+	}
+
+	// Every agent holds a subscription until it is closed
+	release, err := am.app.GetAdmission().Reserve("agents")
+	if err != nil {
+		return nil, err
+	}
 
 	agent := CreateAgent(am.app, transactionID)
+	agent.retransmit = am.retransmit
+	agent.mode = am.mode
+	agent.requestTimeout = am.requestTimeout
+	agent.sequences = am.sequences
+	agent.lastSequence = am.sequences.Last(transactionID)
+	agent.stateTimeout = am.stateTimeout
+	agent.instanceReplies = am.instanceReplies
+
+	am.agents[agent] = struct{}{}
+	agent.onClose = func() {
+		am.mutex.Lock()
+		delete(am.agents, agent)
+		am.mutex.Unlock()
+
+		release()
+	}
+
+	return agent, nil
+}
+
+// Count returns number of agents which are waiting for events
+func (am *AgentManager) Count() int {
+
+	am.mutex.Lock()
+	defer am.mutex.Unlock()
 
-	return agent
+	return len(am.agents)
+}
+
+// CloseAll unsubscribes all agents, so that everyone who is waiting for events
+// gives up immediately.
+func (am *AgentManager) CloseAll() {
+
+	am.mutex.Lock()
+	am.closed = true
+	am.mutex.Unlock()
+
+	am.abortAll()
+}
+
+// abortAll closes agents which exist now, new agents can still be created
+func (am *AgentManager) abortAll() {
+
+	am.mutex.Lock()
+	agents := make([]*Agent, 0, len(am.agents))
+	for agent := range am.agents {
+		agents = append(agents, agent)
+	}
+	am.mutex.Unlock()
+
+	for _, agent := range agents {
+		agent.CloseEventChannel()
+	}
 }
@@ -0,0 +1,273 @@
+package commander
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	app "twist-commander/app/interface"
+	pb "twist-commander/pb"
+)
+
+// ackingBus acknowledges command before Emit returns, like a fast runner
+type ackingBus struct {
+	app.SignalBusImpl
+	app   *testApp
+	agent *Agent
+}
+
+func (bus *ackingBus) Emit(topic string, data []byte) error {
+
+	var cmd pb.TransactionCommand
+	bus.app.codec.Decode(data, &cmd)
+
+	ack, _ := bus.app.codec.Encode(&pb.TransactionEvent{
+		TransactionID: cmd.TransactionID,
+		Type:          pb.TransactionEventType_EVENT_COMMAND_RECEIVED,
+		Detail: &pb.TransactionEvent_Receipt{
+			Receipt: &pb.CommandReceipt{CommandID: cmd.CommandID},
+		},
+	})
+
+	bus.agent.handleMessage(&app.Message{Subject: topic, Data: ack})
+
+	return nil
+}
+
+func TestSendCommandAcknowledgedBeforeEmitReturns(t *testing.T) {
+
+	a := createTestApp(t)
+	defer a.bus.Close()
+
+	agent := CreateAgent(a, "tx1")
+	agent.retransmit = RetransmitPolicy{AckTimeout: 20 * time.Millisecond, MaxAttempts: 1}
+	defer agent.CloseEventChannel()
+
+	a.signalBus = &ackingBus{SignalBusImpl: a.bus, app: a, agent: agent}
+
+	err := agent.SendCommand("confirm", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if err := agent.Err(); err != nil {
+		t.Errorf("Acknowledged command failed: %v", err)
+	}
+}
+
+func TestCheckSequence(t *testing.T) {
+
+	a := createTestApp(t)
+	defer a.bus.Close()
+	am := CreateAgentManager(a)
+
+	// Events of earlier request
+	first, _ := am.CreateAgent("tx1")
+	for _, sequence := range []uint64{1, 2, 3} {
+		if !first.checkSequence(&pb.TransactionEvent{Sequence: sequence}) {
+			t.Fatalf("Event %d was dropped", sequence)
+		}
+	}
+	first.CloseEventChannel()
+
+	// Concurrent requests see the same new events, but not the old ones
+	agents := make([]*Agent, 2)
+	for i := range agents {
+		agents[i], _ = am.CreateAgent("tx1")
+		defer agents[i].CloseEventChannel()
+	}
+
+	for _, agent := range agents {
+		if agent.checkSequence(&pb.TransactionEvent{Sequence: 3}) {
+			t.Error("Event seen by earlier request was delivered")
+		}
+
+		if !agent.checkSequence(&pb.TransactionEvent{Sequence: 4}) {
+			t.Error("New event was dropped")
+		}
+
+		if agent.checkSequence(&pb.TransactionEvent{Sequence: 4}) {
+			t.Error("Duplicate event was delivered")
+		}
+	}
+}
+
+func TestQueryStateTimeout(t *testing.T) {
+
+	a := createTestApp(t)
+	defer a.bus.Close()
+	am := CreateAgentManager(a)
+	am.stateTimeout = 20 * time.Millisecond
+
+	agent, _ := am.CreateAgent("tx1")
+	defer agent.CloseEventChannel()
+
+	agent.checkSequence(&pb.TransactionEvent{Sequence: 1})
+	agent.checkSequence(&pb.TransactionEvent{Sequence: 3})
+
+	// Runner never reports state
+	select {
+	case _, ok := <-agent.EventChannel:
+		if ok {
+			t.Fatal("Unexpected event")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Agent was not closed")
+	}
+
+	if status.Code(agent.Err()) != codes.DeadlineExceeded {
+		t.Errorf("Expected deadline exceeded, got %v", agent.Err())
+	}
+}
+
+func TestQueryStateReported(t *testing.T) {
+
+	a := createTestApp(t)
+	defer a.bus.Close()
+	am := CreateAgentManager(a)
+	am.stateTimeout = 100 * time.Millisecond
+
+	queried := make(chan struct{}, 1)
+	a.bus.Watch("twist.transaction.tx1.cmdReceived", "runner", func(*app.Message) {
+		queried <- struct{}{}
+	})
+
+	agent, _ := am.CreateAgent("tx1")
+	defer agent.CloseEventChannel()
+
+	agent.checkSequence(&pb.TransactionEvent{Sequence: 1})
+	agent.checkSequence(&pb.TransactionEvent{Sequence: 3})
+
+	<-queried
+
+	// State at the sequence of the event after the gap includes missed events
+	go agent.restoreState(&pb.TransactionEvent{
+		Detail: &pb.TransactionEvent_State{
+			State: &pb.TransactionState{State: OutcomeConfirmed, Sequence: 3},
+		},
+	})
+
+	event := <-agent.EventChannel
+	if event.Type != pb.TransactionEventType_EVENT_CONFIRMED {
+		t.Errorf("Unexpected event %v", event)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+
+	if err := agent.Err(); err != nil {
+		t.Errorf("Reported state failed request: %v", err)
+	}
+}
+
+func TestStateReportedWithoutQuery(t *testing.T) {
+
+	a := createTestApp(t)
+	defer a.bus.Close()
+	am := CreateAgentManager(a)
+
+	agent, _ := am.CreateAgent("tx1")
+	defer agent.CloseEventChannel()
+
+	agent.checkSequence(&pb.TransactionEvent{Sequence: 9})
+
+	// State queried by another agent of transaction
+	go agent.restoreState(&pb.TransactionEvent{
+		Detail: &pb.TransactionEvent_State{
+			State: &pb.TransactionState{State: "TasksRegistered", Sequence: 10},
+		},
+	})
+
+	select {
+	case event := <-agent.EventChannel:
+		t.Errorf("State which was not queried was delivered: %v", event)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	agent.mutex.Lock()
+	defer agent.mutex.Unlock()
+
+	if agent.lastSequence != 9 {
+		t.Errorf("Expected last sequence 9, got %d", agent.lastSequence)
+	}
+}
+
+func TestStateReportedWithOlderSequence(t *testing.T) {
+
+	a := createTestApp(t)
+	defer a.bus.Close()
+	am := CreateAgentManager(a)
+	am.stateTimeout = time.Second
+
+	queried := make(chan struct{}, 1)
+	a.bus.Watch("twist.transaction.tx1.cmdReceived", "runner", func(*app.Message) {
+		queried <- struct{}{}
+	})
+
+	agent, _ := am.CreateAgent("tx1")
+	defer agent.CloseEventChannel()
+
+	agent.checkSequence(&pb.TransactionEvent{Sequence: 7})
+	agent.checkSequence(&pb.TransactionEvent{Sequence: 9})
+
+	<-queried
+
+	// Answer to an earlier query is older than the events which were delivered
+	go agent.restoreState(&pb.TransactionEvent{
+		Detail: &pb.TransactionEvent_State{
+			State: &pb.TransactionState{State: "TasksRegistered", Sequence: 3},
+		},
+	})
+
+	select {
+	case event := <-agent.EventChannel:
+		t.Errorf("Stale state was delivered: %v", event)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	agent.mutex.Lock()
+	defer agent.mutex.Unlock()
+
+	if agent.lastSequence != 9 {
+		t.Errorf("Expected last sequence 9, got %d", agent.lastSequence)
+	}
+
+	if agent.stateQuery == nil {
+		t.Error("Stale state answered the query")
+	}
+}
+
+func TestInstanceRepliesSkipGapDetection(t *testing.T) {
+
+	a := createTestApp(t)
+	defer a.bus.Close()
+	am := CreateAgentManager(a)
+	am.instanceReplies = true
+
+	queried := make(chan struct{}, 1)
+	a.bus.Watch("twist.transaction.tx1.cmdReceived", "runner", func(*app.Message) {
+		queried <- struct{}{}
+	})
+
+	agent, _ := am.CreateAgent("tx1")
+	defer agent.CloseEventChannel()
+
+	// Event 2 was caused by command of another instance
+	agent.checkSequence(&pb.TransactionEvent{Sequence: 1})
+	if !agent.checkSequence(&pb.TransactionEvent{Sequence: 3}) {
+		t.Fatal("Event after gap was dropped")
+	}
+
+	if agent.checkSequence(&pb.TransactionEvent{Sequence: 3}) {
+		t.Error("Duplicate event was delivered")
+	}
+
+	select {
+	case <-queried:
+		t.Error("State was queried")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
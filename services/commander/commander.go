@@ -7,6 +7,8 @@ import (
 
 	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/any"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type Commander struct {
@@ -21,16 +23,27 @@ func CreateCommander(a app.AppImpl) *Commander {
 	}
 }
 
-func (c *Commander) CreateRequest(transactionID string, command string, payload *any.Any) (*Agent, error) {
+// Close makes all pending requests give up
+func (c *Commander) Close() {
+	c.agentMgr.CloseAll()
+}
 
-	agent := c.agentMgr.CreateAgent(transactionID)
+func (c *Commander) CreateRequest(transactionID string, command string, payload *any.Any) (*Agent, error) {
 
-	// Getting event channel
-	err := agent.OpenEventChannel()
+	agent, err := c.agentMgr.CreateAgent(transactionID)
 	if err != nil {
 		return nil, err
 	}
 
+	// Getting event channel, reply comes to inbox of the request otherwise
+	if agent.mode != CommandModeRequest {
+		err = agent.OpenEventChannel()
+		if err != nil {
+			agent.CloseEventChannel()
+			return nil, err
+		}
+	}
+
 	// Send command
 	err = agent.SendCommand(command, payload)
 	if err != nil {
@@ -41,44 +54,62 @@ func (c *Commander) CreateRequest(transactionID string, command string, payload
 	return agent, nil
 }
 
-func (c *Commander) ConfirmTransaction(transactionID string, payload *pb.ConfirmTransactionRequest) error {
+func (c *Commander) ConfirmTransaction(transactionID string, payload *pb.ConfirmTransactionRequest) (*TransactionOutcome, error) {
 
 	data, err := ptypes.MarshalAny(payload)
 	if err != nil {
-		return errors.New("Failed to handle payload")
+		return nil, errors.New("Failed to handle payload")
 	}
 
 	request, err := c.CreateRequest(transactionID, "confirm", data)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	defer request.CloseEventChannel()
 
 	success := false
+	var outcome *TransactionOutcome
 
 COMPLETED:
 	for {
 		select {
-		case event := <-request.EventChannel:
+		case event, ok := <-request.EventChannel:
 
-			switch event.EventName {
-			case "Confirmed":
-				success = true
+			// Agent was closed
+			if !ok {
 				break COMPLETED
-			case "Canceled":
+			}
+
+			switch event.Type {
+			case pb.TransactionEventType_EVENT_CONFIRMED, pb.TransactionEventType_EVENT_HEURISTIC_COMMIT:
+				success = true
+				outcome = createOutcome(event)
 				break COMPLETED
-			case "Timeout":
+			case pb.TransactionEventType_EVENT_CANCELED, pb.TransactionEventType_EVENT_TIMEOUT,
+				pb.TransactionEventType_EVENT_HEURISTIC_MIXED, pb.TransactionEventType_EVENT_HEURISTIC_ROLLBACK:
+				outcome = createOutcome(event)
 				break COMPLETED
 			}
 		}
 	}
 
+	// Transaction is finished, its events are not expected anymore
+	if outcome != nil {
+		c.agentMgr.sequences.Forget(transactionID)
+	}
+
 	if success == false {
-		return errors.New("Failed to confirm transaction")
+
+		// Runner did not acknowledge the command
+		if err := request.Err(); err != nil {
+			return nil, err
+		}
+
+		return outcome, errors.New("Failed to confirm transaction")
 	}
 
-	return nil
+	return outcome, nil
 }
 
 func (c *Commander) RegisterTasks(transactionID string, payload *pb.RegisterTasksRequest) error {
@@ -100,8 +131,14 @@ func (c *Commander) RegisterTasks(transactionID string, payload *pb.RegisterTask
 COMPLETED:
 	for {
 		select {
-		case event := <-request.EventChannel:
-			if event.EventName == "TasksRegistered" {
+		case event, ok := <-request.EventChannel:
+
+			// Agent was closed
+			if !ok {
+				break COMPLETED
+			}
+
+			if event.Type == pb.TransactionEventType_EVENT_TASKS_REGISTERED {
 				success = true
 				break COMPLETED
 			}
@@ -109,20 +146,79 @@ COMPLETED:
 	}
 
 	if success == false {
+
+		// Runner did not acknowledge the command
+		if err := request.Err(); err != nil {
+			return err
+		}
+
 		return errors.New("Failed to register tasks")
 	}
 
 	return nil
 }
 
-func (c *Commander) CancelTransaction(transactionID string, payload *pb.CancelTransactionRequest) error {
+func (c *Commander) ListTasks(transactionID string, payload *pb.ListTasksRequest) ([]*pb.TransactionTask, error) {
+
+	data, err := ptypes.MarshalAny(payload)
+	if err != nil {
+		return nil, errors.New("Failed to handle payload")
+	}
+
+	request, err := c.CreateRequest(transactionID, "listTasks", data)
+	if err != nil {
+		return nil, err
+	}
+
+	defer request.CloseEventChannel()
+
+	success := false
+	var taskList *pb.TransactionTaskList
+
+COMPLETED:
+	for {
+		select {
+		case event, ok := <-request.EventChannel:
+
+			// Agent was closed
+			if !ok {
+				break COMPLETED
+			}
+
+			if event.Type == pb.TransactionEventType_EVENT_TASKS_LISTED {
+
+				taskList = event.GetTaskList()
+				if taskList == nil {
+					return nil, errors.New("Failed to parse task list")
+				}
+
+				success = true
+				break COMPLETED
+			}
+		}
+	}
+
+	if success == false {
+
+		// Runner did not acknowledge the command
+		if err := request.Err(); err != nil {
+			return nil, err
+		}
+
+		return nil, errors.New("Failed to list tasks")
+	}
+
+	return taskList.Tasks, nil
+}
+
+func (c *Commander) ReplaceTasks(transactionID string, payload *pb.ReplaceTasksRequest) error {
 
 	data, err := ptypes.MarshalAny(payload)
 	if err != nil {
 		return errors.New("Failed to handle payload")
 	}
 
-	request, err := c.CreateRequest(transactionID, "cancel", data)
+	request, err := c.CreateRequest(transactionID, "replaceTasks", data)
 	if err != nil {
 		return err
 	}
@@ -134,20 +230,166 @@ func (c *Commander) CancelTransaction(transactionID string, payload *pb.CancelTr
 COMPLETED:
 	for {
 		select {
-		case event := <-request.EventChannel:
-			switch event.EventName {
-			case "Canceled":
+		case event, ok := <-request.EventChannel:
+
+			// Agent was closed
+			if !ok {
+				break COMPLETED
+			}
+
+			if event.Type == pb.TransactionEventType_EVENT_TASKS_REPLACED {
 				success = true
 				break COMPLETED
-			case "Timeout":
+			}
+		}
+	}
+
+	if success == false {
+
+		// Runner did not acknowledge the command
+		if err := request.Err(); err != nil {
+			return err
+		}
+
+		return errors.New("Failed to replace tasks")
+	}
+
+	return nil
+}
+
+func (c *Commander) RemoveTask(transactionID string, payload *pb.RemoveTaskRequest) error {
+
+	data, err := ptypes.MarshalAny(payload)
+	if err != nil {
+		return errors.New("Failed to handle payload")
+	}
+
+	request, err := c.CreateRequest(transactionID, "removeTask", data)
+	if err != nil {
+		return err
+	}
+
+	defer request.CloseEventChannel()
+
+	success := false
+
+COMPLETED:
+	for {
+		select {
+		case event, ok := <-request.EventChannel:
+
+			// Agent was closed
+			if !ok {
+				break COMPLETED
+			}
+
+			switch event.Type {
+			case pb.TransactionEventType_EVENT_TASK_REMOVED:
+				success = true
 				break COMPLETED
+			case pb.TransactionEventType_EVENT_TASK_NOT_FOUND:
+				return status.Error(codes.NotFound, "Task not found")
 			}
 		}
 	}
 
 	if success == false {
-		return errors.New("Failed to register tasks")
+
+		// Runner did not acknowledge the command
+		if err := request.Err(); err != nil {
+			return err
+		}
+
+		return errors.New("Failed to remove task")
 	}
 
 	return nil
 }
+
+func (c *Commander) ForceResolve(transactionID string, payload *pb.ForceResolveRequest) error {
+
+	data, err := ptypes.MarshalAny(payload)
+	if err != nil {
+		return errors.New("Failed to handle payload")
+	}
+
+	agent, err := c.agentMgr.CreateAgent(transactionID)
+	if err != nil {
+		return err
+	}
+
+	defer agent.CloseEventChannel()
+
+	cmd := agent.createCommand("forceResolve", data)
+
+	// Runner might be dead already, so no reply is expected
+	if agent.mode != CommandModeRequest {
+		return agent.emitCommand(cmd)
+	}
+
+	// Published only if runner never acknowledged the request
+	_, err = agent.awaitReply(cmd)
+	if status.Code(err) == codes.DeadlineExceeded {
+		return agent.emitCommand(cmd)
+	}
+
+	return err
+}
+
+func (c *Commander) CancelTransaction(transactionID string, payload *pb.CancelTransactionRequest) (*TransactionOutcome, error) {
+
+	data, err := ptypes.MarshalAny(payload)
+	if err != nil {
+		return nil, errors.New("Failed to handle payload")
+	}
+
+	request, err := c.CreateRequest(transactionID, "cancel", data)
+	if err != nil {
+		return nil, err
+	}
+
+	defer request.CloseEventChannel()
+
+	success := false
+	var outcome *TransactionOutcome
+
+COMPLETED:
+	for {
+		select {
+		case event, ok := <-request.EventChannel:
+
+			// Agent was closed
+			if !ok {
+				break COMPLETED
+			}
+
+			switch event.Type {
+			case pb.TransactionEventType_EVENT_CANCELED, pb.TransactionEventType_EVENT_HEURISTIC_ROLLBACK:
+				success = true
+				outcome = createOutcome(event)
+				break COMPLETED
+			case pb.TransactionEventType_EVENT_TIMEOUT, pb.TransactionEventType_EVENT_HEURISTIC_MIXED,
+				pb.TransactionEventType_EVENT_HEURISTIC_COMMIT:
+				outcome = createOutcome(event)
+				break COMPLETED
+			}
+		}
+	}
+
+	// Transaction is finished, its events are not expected anymore
+	if outcome != nil {
+		c.agentMgr.sequences.Forget(transactionID)
+	}
+
+	if success == false {
+
+		// Runner did not acknowledge the command
+		if err := request.Err(); err != nil {
+			return nil, err
+		}
+
+		return outcome, errors.New("Failed to cancel transaction")
+	}
+
+	return outcome, nil
+}
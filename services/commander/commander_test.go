@@ -0,0 +1,459 @@
+package commander
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"twist-commander/app/admission"
+	"twist-commander/app/codec"
+	app "twist-commander/app/interface"
+	"twist-commander/app/signalbus"
+	"twist-commander/app/store"
+	"twist-commander/app/subject"
+	pb "twist-commander/pb"
+)
+
+// testApp runs commander on in-memory signal bus
+type testApp struct {
+	bus       *signalbus.MemoryBus
+	signalBus app.SignalBusImpl
+	subjects  *subject.Builder
+	codec     *codec.Codec
+	admission *admission.Controller
+	store     app.TransactionStore
+	token     string
+}
+
+func createTestApp(t *testing.T) *testApp {
+
+	bus := signalbus.CreateMemoryBus(signalbus.Options{})
+	if err := bus.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	subjects, _ := subject.CreateBuilder("", "")
+	c, _ := codec.CreateCodec("", "commander")
+
+	return &testApp{
+		bus:       bus,
+		signalBus: bus,
+		subjects:  subjects,
+		codec:     c,
+		admission: admission.CreateController(admission.Options{}, 0),
+		store:     store.CreateMemoryStore(0, 0),
+	}
+}
+
+func (a *testApp) GetSignalBus() app.SignalBusImpl           { return a.signalBus }
+func (a *testApp) GetSupervisorClient() app.SupervisorClient { return nil }
+func (a *testApp) GetAdmission() app.AdmissionImpl           { return a.admission }
+func (a *testApp) GetSubjects() app.SubjectImpl              { return a.subjects }
+func (a *testApp) GetCodec() app.CodecImpl                   { return a.codec }
+func (a *testApp) GetInstanceID() string                     { return "1" }
+func (a *testApp) GetAdminToken() string                     { return a.token }
+func (a *testApp) GetStore() app.TransactionStore            { return a.store }
+
+// fakeRunner acknowledges commands and completes them with events, like a
+// runner which handles every command successfully
+type fakeRunner struct {
+	app      *testApp
+	codec    *codec.Codec
+	sequence uint64
+	commands chan *pb.TransactionCommand
+}
+
+var commandEvents = map[string]pb.TransactionEventType{
+	"registerTasks": pb.TransactionEventType_EVENT_TASKS_REGISTERED,
+	"confirm":       pb.TransactionEventType_EVENT_CONFIRMED,
+	"removeTask":    pb.TransactionEventType_EVENT_TASK_NOT_FOUND,
+}
+
+// startFakeRunner starts runner which answers requests, and published commands
+// only if requests is false
+func startFakeRunner(t *testing.T, a *testApp, requests bool) *fakeRunner {
+
+	c, _ := codec.CreateCodec("", "runner")
+
+	r := &fakeRunner{
+		app:      a,
+		codec:    c,
+		commands: make(chan *pb.TransactionCommand, 10),
+	}
+
+	topics := []string{"twist.transaction.*.cmdReceived"}
+	if requests {
+		topics = append(topics, "twist.transaction.*.cmdRequest")
+	}
+
+	for _, topic := range topics {
+		_, err := a.bus.Watch(topic, "runner", r.handleCommand)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return r
+}
+
+func (r *fakeRunner) handleCommand(msg *app.Message) {
+
+	var cmd pb.TransactionCommand
+	if err := r.codec.Decode(msg.Data, &cmd); err != nil {
+		return
+	}
+
+	r.commands <- &cmd
+
+	event := &pb.TransactionEvent{
+		TransactionID: cmd.TransactionID,
+		Type:          commandEvents[cmd.Command],
+	}
+
+	// Reply to request
+	if msg.Reply != "" {
+		r.app.bus.Emit(msg.Reply, r.encode(event))
+		return
+	}
+
+	topic, _ := r.app.subjects.TransactionEvents(cmd.TransactionID)
+
+	r.app.bus.Emit(topic, r.encode(&pb.TransactionEvent{
+		TransactionID: cmd.TransactionID,
+		Type:          pb.TransactionEventType_EVENT_COMMAND_RECEIVED,
+		Detail: &pb.TransactionEvent_Receipt{
+			Receipt: &pb.CommandReceipt{CommandID: cmd.CommandID},
+		},
+	}))
+
+	r.sequence++
+	event.Sequence = r.sequence
+
+	r.app.bus.Emit(topic, r.encode(event))
+}
+
+func (r *fakeRunner) encode(event *pb.TransactionEvent) []byte {
+	data, _ := r.codec.Encode(event)
+	return data
+}
+
+func (r *fakeRunner) nextCommand(t *testing.T) *pb.TransactionCommand {
+
+	select {
+	case cmd := <-r.commands:
+		return cmd
+	case <-time.After(time.Second):
+		t.Fatal("Runner did not receive command")
+	}
+
+	return nil
+}
+
+func TestCommanderFlow(t *testing.T) {
+
+	for _, mode := range []string{CommandModePublish, CommandModeRequest} {
+		t.Run(mode, func(t *testing.T) {
+
+			a := createTestApp(t)
+			defer a.bus.Close()
+			runner := startFakeRunner(t, a, true)
+
+			c := CreateCommander(a)
+			c.agentMgr.mode = mode
+			c.agentMgr.retransmit.AckTimeout = time.Second
+			defer c.Close()
+
+			err := c.RegisterTasks("tx1", &pb.RegisterTasksRequest{
+				TransactionID: "tx1",
+				Tasks:         []*pb.TransactionTask{validTask("task1")},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if cmd := runner.nextCommand(t); cmd.Command != "registerTasks" || cmd.InstanceID != "1" {
+				t.Errorf("Unexpected command %v", cmd)
+			}
+
+			outcome, err := c.ConfirmTransaction("tx1", &pb.ConfirmTransactionRequest{TransactionID: "tx1"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if outcome.Name != OutcomeConfirmed {
+				t.Errorf("Expected confirmed outcome, got %s", outcome.Name)
+			}
+
+			if cmd := runner.nextCommand(t); cmd.Command != "confirm" {
+				t.Errorf("Unexpected command %v", cmd)
+			}
+
+			// Every agent is gone with its subscriptions
+			if c.agentMgr.Count() != 0 {
+				t.Errorf("Expected no agents, got %d", c.agentMgr.Count())
+			}
+
+			if a.bus.SubscriptionCount() != 2 {
+				t.Errorf("Expected only subscriptions of runner, got %d", a.bus.SubscriptionCount())
+			}
+		})
+	}
+}
+
+func TestRemoveMissingTask(t *testing.T) {
+
+	a := createTestApp(t)
+	defer a.bus.Close()
+	startFakeRunner(t, a, false)
+
+	c := CreateCommander(a)
+	c.agentMgr.retransmit.AckTimeout = time.Second
+	defer c.Close()
+
+	err := c.RemoveTask("tx1", &pb.RemoveTaskRequest{TransactionID: "tx1", TaskID: "missing"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("Expected not found, got %v", err)
+	}
+}
+
+func TestCommanderFallsBackToPublish(t *testing.T) {
+
+	a := createTestApp(t)
+	defer a.bus.Close()
+	runner := startFakeRunner(t, a, false)
+
+	c := CreateCommander(a)
+	c.agentMgr.mode = CommandModeRequest
+	c.agentMgr.requestTimeout = 50 * time.Millisecond
+	defer c.Close()
+
+	err := c.RegisterTasks("tx1", &pb.RegisterTasksRequest{
+		TransactionID: "tx1",
+		Tasks:         []*pb.TransactionTask{validTask("task1")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cmd := runner.nextCommand(t); cmd.Command != "registerTasks" || cmd.Attempt != 2 {
+		t.Errorf("Unexpected command %v", cmd)
+	}
+}
+
+func TestCommanderWaitsForAcknowledgedRequest(t *testing.T) {
+
+	a := createTestApp(t)
+	defer a.bus.Close()
+
+	c, _ := codec.CreateCodec("", "runner")
+	published := make(chan struct{}, 1)
+
+	// Runner acknowledges at once, but replies only after request timeout
+	a.bus.Watch("twist.transaction.*.cmdRequest", "runner", func(msg *app.Message) {
+
+		var cmd pb.TransactionCommand
+		c.Decode(msg.Data, &cmd)
+
+		topic, _ := a.subjects.TransactionEvents(cmd.TransactionID)
+		ack, _ := c.Encode(&pb.TransactionEvent{
+			TransactionID: cmd.TransactionID,
+			Type:          pb.TransactionEventType_EVENT_COMMAND_RECEIVED,
+			Detail: &pb.TransactionEvent_Receipt{
+				Receipt: &pb.CommandReceipt{CommandID: cmd.CommandID},
+			},
+		})
+		a.bus.Emit(topic, ack)
+
+		time.Sleep(150 * time.Millisecond)
+
+		reply, _ := c.Encode(&pb.TransactionEvent{
+			TransactionID: cmd.TransactionID,
+			Type:          pb.TransactionEventType_EVENT_CONFIRMED,
+		})
+		a.bus.Emit(msg.Reply, reply)
+	})
+
+	a.bus.Watch("twist.transaction.*.cmdReceived", "runner", func(msg *app.Message) {
+		published <- struct{}{}
+	})
+
+	commander := CreateCommander(a)
+	commander.agentMgr.mode = CommandModeRequest
+	commander.agentMgr.requestTimeout = 50 * time.Millisecond
+	defer commander.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		outcome, err := commander.ConfirmTransaction("tx1", &pb.ConfirmTransactionRequest{TransactionID: "tx1"})
+		if err == nil && outcome.Name != OutcomeConfirmed {
+			err = errors.New("Unexpected outcome " + outcome.Name)
+		}
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-published:
+		t.Fatal("Acknowledged request was published again")
+	case <-time.After(time.Second):
+		t.Fatal("Reply was not delivered")
+	}
+}
+
+func TestForceResolve(t *testing.T) {
+
+	tests := []struct {
+		mode     string
+		requests bool
+	}{
+		{CommandModePublish, false},
+		{CommandModeRequest, true},
+		{CommandModeRequest, false},
+	}
+
+	for _, test := range tests {
+
+		a := createTestApp(t)
+		runner := startFakeRunner(t, a, test.requests)
+
+		c := CreateCommander(a)
+		c.agentMgr.mode = test.mode
+		c.agentMgr.requestTimeout = 50 * time.Millisecond
+
+		err := c.ForceResolve("tx1", &pb.ForceResolveRequest{TransactionID: "tx1", Outcome: "canceled"})
+		if err != nil {
+			t.Errorf("Mode %s: %v", test.mode, err)
+		}
+
+		if cmd := runner.nextCommand(t); cmd.Command != "forceResolve" {
+			t.Errorf("Mode %s: unexpected command %v", test.mode, cmd)
+		}
+
+		c.Close()
+		a.bus.Close()
+	}
+}
+
+func TestServiceConfirmRecordsTasks(t *testing.T) {
+
+	a := createTestApp(t)
+	defer a.bus.Close()
+	runner := startFakeRunner(t, a, false)
+
+	service := CreateService(a)
+	defer service.Close()
+
+	a.store.Create(&app.Transaction{TransactionID: "tx1"})
+
+	task := validTask("")
+
+	reply, err := service.ConfirmTransaction(context.Background(), &pb.ConfirmTransactionRequest{
+		TransactionID: "tx1",
+		Tasks:         []*pb.TransactionTask{task},
+	})
+	if err != nil || !reply.Success {
+		t.Fatalf("Unexpected reply %v, %v", reply, err)
+	}
+
+	var payload pb.ConfirmTransactionRequest
+	if err := ptypes.UnmarshalAny(runner.nextCommand(t).Payload, &payload); err != nil {
+		t.Fatal(err)
+	}
+
+	tx, _ := a.store.Get("tx1")
+	if len(tx.Tasks) != 1 || tx.Tasks[0].Id == "" {
+		t.Fatalf("Expected task with ID to be recorded, got %v", tx.Tasks)
+	}
+
+	// Runner and store know the task by the same ID
+	if len(payload.Tasks) != 1 || payload.Tasks[0].Id != tx.Tasks[0].Id {
+		t.Errorf("Runner received tasks %v", payload.Tasks)
+	}
+}
+
+func TestServiceForceResolveOutcomeCase(t *testing.T) {
+
+	a := createTestApp(t)
+	defer a.bus.Close()
+	a.token = "secret"
+	runner := startFakeRunner(t, a, false)
+
+	service := CreateService(a)
+	defer service.Close()
+
+	a.store.Create(&app.Transaction{TransactionID: "tx1"})
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "secret"))
+	reply, err := service.ForceResolve(ctx, &pb.ForceResolveRequest{
+		TransactionID: "tx1",
+		Outcome:       OutcomeCanceled,
+		RedriveCancel: true,
+		Operator:      "ops",
+		Reason:        "stuck",
+	})
+	if err != nil || !reply.Success {
+		t.Fatalf("Unexpected reply %v, %v", reply, err)
+	}
+
+	var payload pb.ForceResolveRequest
+	if err := ptypes.UnmarshalAny(runner.nextCommand(t).Payload, &payload); err != nil {
+		t.Fatal(err)
+	}
+
+	if payload.Outcome != "canceled" {
+		t.Errorf("Expected lowercase outcome for runner, got %s", payload.Outcome)
+	}
+
+	tx, _ := a.store.Get("tx1")
+	if tx.Outcome != OutcomeCanceled {
+		t.Errorf("Expected canceled outcome, got %s", tx.Outcome)
+	}
+}
+
+func TestServiceRejectsInvalidTransactionID(t *testing.T) {
+
+	a := createTestApp(t)
+	defer a.bus.Close()
+
+	service := CreateService(a)
+	defer service.Close()
+
+	_, err := service.ListTasks(context.Background(), &pb.ListTasksRequest{TransactionID: "tx.*"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected invalid argument, got %v", err)
+	}
+}
+
+// failingBus fails every request, like connection lost while waiting
+type failingBus struct {
+	app.SignalBusImpl
+}
+
+func (bus *failingBus) Request(ctx context.Context, topic string, data []byte) ([]byte, error) {
+	return nil, errors.New("Connection closed")
+}
+
+func TestForceResolveFailedRequest(t *testing.T) {
+
+	a := createTestApp(t)
+	defer a.bus.Close()
+	a.signalBus = &failingBus{SignalBusImpl: a.bus}
+
+	c := CreateCommander(a)
+	c.agentMgr.mode = CommandModeRequest
+	defer c.Close()
+
+	err := c.ForceResolve("tx1", &pb.ForceResolveRequest{TransactionID: "tx1", Outcome: "canceled"})
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("Expected unavailable, got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package commander
+
+import (
+	pb "twist-commander/pb"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	log "github.com/sirupsen/logrus"
+)
+
+// Names of event types in legacy string form
+var eventTypeNames = map[pb.TransactionEventType]string{
+	pb.TransactionEventType_EVENT_ASSIGNED:           "Assigned",
+	pb.TransactionEventType_EVENT_TASKS_REGISTERED:   "TasksRegistered",
+	pb.TransactionEventType_EVENT_TASKS_LISTED:       "TasksListed",
+	pb.TransactionEventType_EVENT_TASKS_REPLACED:     "TasksReplaced",
+	pb.TransactionEventType_EVENT_TASK_REMOVED:       "TaskRemoved",
+	pb.TransactionEventType_EVENT_TASK_NOT_FOUND:     "TaskNotFound",
+	pb.TransactionEventType_EVENT_CONFIRMED:          OutcomeConfirmed,
+	pb.TransactionEventType_EVENT_CANCELED:           OutcomeCanceled,
+	pb.TransactionEventType_EVENT_TIMEOUT:            OutcomeTimeout,
+	pb.TransactionEventType_EVENT_HEURISTIC_MIXED:    OutcomeHeuristicMixed,
+	pb.TransactionEventType_EVENT_HEURISTIC_COMMIT:   OutcomeHeuristicCommit,
+	pb.TransactionEventType_EVENT_HEURISTIC_ROLLBACK: OutcomeHeuristicRollback,
+	pb.TransactionEventType_EVENT_COMMAND_RECEIVED:   "CommandReceived",
+	pb.TransactionEventType_EVENT_STATE_REPORTED:     "StateReported",
+}
+
+var eventTypes = createEventTypes()
+
+func createEventTypes() map[string]pb.TransactionEventType {
+
+	types := make(map[string]pb.TransactionEventType)
+	for eventType, name := range eventTypeNames {
+		types[name] = eventType
+	}
+
+	return types
+}
+
+// normalizeEvent converts legacy string form of event into the typed one and
+// the other way round, so both kinds of runners are served while migrating
+func normalizeEvent(event *pb.TransactionEvent) {
+
+	if event.Type == pb.TransactionEventType_EVENT_UNKNOWN {
+		event.Type = eventTypes[event.EventName]
+
+		if event.Detail == nil {
+			decodeLegacyPayload(event)
+		}
+	}
+
+	if event.EventName == "" {
+		event.EventName = eventTypeNames[event.Type]
+	}
+}
+
+// decodeLegacyPayload parses string payload into typed detail of event
+func decodeLegacyPayload(event *pb.TransactionEvent) {
+
+	switch event.Type {
+	case pb.TransactionEventType_EVENT_ASSIGNED:
+		if event.RunnerID != "" {
+			event.Detail = &pb.TransactionEvent_Runner{
+				Runner: &pb.RunnerInfo{RunnerID: event.RunnerID},
+			}
+		}
+	case pb.TransactionEventType_EVENT_COMMAND_RECEIVED:
+		event.Detail = &pb.TransactionEvent_Receipt{
+			Receipt: &pb.CommandReceipt{CommandID: event.Payload},
+		}
+	case pb.TransactionEventType_EVENT_TASKS_LISTED:
+		var taskList pb.TransactionTaskList
+		if parseLegacyPayload(event, &taskList) {
+			event.Detail = &pb.TransactionEvent_TaskList{TaskList: &taskList}
+		}
+	case pb.TransactionEventType_EVENT_STATE_REPORTED:
+		var state pb.TransactionState
+		if parseLegacyPayload(event, &state) {
+			event.Detail = &pb.TransactionEvent_State{State: &state}
+		}
+	case pb.TransactionEventType_EVENT_CONFIRMED,
+		pb.TransactionEventType_EVENT_CANCELED,
+		pb.TransactionEventType_EVENT_TIMEOUT,
+		pb.TransactionEventType_EVENT_HEURISTIC_MIXED,
+		pb.TransactionEventType_EVENT_HEURISTIC_COMMIT,
+		pb.TransactionEventType_EVENT_HEURISTIC_ROLLBACK:
+
+		// Outcome might carry results of tasks, otherwise payload is kept as is
+		var results pb.TaskResults
+		if event.Payload != "" && jsonpb.UnmarshalString(event.Payload, &results) == nil {
+			event.Detail = &pb.TransactionEvent_TaskResults{TaskResults: &results}
+		}
+	}
+}
+
+func parseLegacyPayload(event *pb.TransactionEvent, detail proto.Message) bool {
+
+	err := jsonpb.UnmarshalString(event.Payload, detail)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"transactionID": event.TransactionID,
+			"eventName":     event.EventName,
+			"error":         err,
+		}).Warn("Failed to parse payload of event")
+
+		return false
+	}
+
+	return true
+}
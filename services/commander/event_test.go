@@ -0,0 +1,127 @@
+package commander
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+
+	pb "twist-commander/pb"
+)
+
+func TestNormalizeLegacyEvent(t *testing.T) {
+
+	cases := []struct {
+		event     *pb.TransactionEvent
+		eventType pb.TransactionEventType
+		detail    *pb.TransactionEvent
+	}{
+		{
+			&pb.TransactionEvent{EventName: "Assigned", RunnerID: "runner1"},
+			pb.TransactionEventType_EVENT_ASSIGNED,
+			&pb.TransactionEvent{Detail: &pb.TransactionEvent_Runner{
+				Runner: &pb.RunnerInfo{RunnerID: "runner1"},
+			}},
+		},
+		{
+			&pb.TransactionEvent{EventName: "CommandReceived", Payload: "cmd1"},
+			pb.TransactionEventType_EVENT_COMMAND_RECEIVED,
+			&pb.TransactionEvent{Detail: &pb.TransactionEvent_Receipt{
+				Receipt: &pb.CommandReceipt{CommandID: "cmd1"},
+			}},
+		},
+		{
+			&pb.TransactionEvent{EventName: "TasksListed", Payload: `{"tasks":[{"id":"task1"}]}`},
+			pb.TransactionEventType_EVENT_TASKS_LISTED,
+			&pb.TransactionEvent{Detail: &pb.TransactionEvent_TaskList{
+				TaskList: &pb.TransactionTaskList{Tasks: []*pb.TransactionTask{{Id: "task1"}}},
+			}},
+		},
+		{
+			&pb.TransactionEvent{EventName: "StateReported", Payload: `{"state":"Confirmed","sequence":"3"}`},
+			pb.TransactionEventType_EVENT_STATE_REPORTED,
+			&pb.TransactionEvent{Detail: &pb.TransactionEvent_State{
+				State: &pb.TransactionState{State: "Confirmed", Sequence: 3},
+			}},
+		},
+		{
+			&pb.TransactionEvent{EventName: "Confirmed", Payload: `{"results":[{"taskID":"task1","action":"confirm","success":true}]}`},
+			pb.TransactionEventType_EVENT_CONFIRMED,
+			&pb.TransactionEvent{Detail: &pb.TransactionEvent_TaskResults{
+				TaskResults: &pb.TaskResults{Results: []*pb.TaskResult{{TaskID: "task1", Action: "confirm", Success: true}}},
+			}},
+		},
+
+		// Payload which is not JSON is kept as is
+		{
+			&pb.TransactionEvent{EventName: "Canceled", Payload: "done"},
+			pb.TransactionEventType_EVENT_CANCELED,
+			&pb.TransactionEvent{},
+		},
+		{
+			&pb.TransactionEvent{EventName: "StateReported", Payload: "garbage"},
+			pb.TransactionEventType_EVENT_STATE_REPORTED,
+			&pb.TransactionEvent{},
+		},
+		{
+			&pb.TransactionEvent{EventName: "Heartbeat"},
+			pb.TransactionEventType_EVENT_UNKNOWN,
+			&pb.TransactionEvent{},
+		},
+	}
+
+	for _, c := range cases {
+		name := c.event.EventName
+		payload := c.event.Payload
+
+		normalizeEvent(c.event)
+
+		if c.event.Type != c.eventType {
+			t.Errorf("%s: expected type %v, got %v", name, c.eventType, c.event.Type)
+		}
+
+		if c.event.EventName != name || c.event.Payload != payload {
+			t.Errorf("%s: legacy fields were changed to %q, %q", name, c.event.EventName, c.event.Payload)
+		}
+
+		if !proto.Equal(&pb.TransactionEvent{Detail: c.event.Detail}, c.detail) {
+			t.Errorf("%s: unexpected detail %v", name, c.event.Detail)
+		}
+	}
+}
+
+func TestNormalizeTypedEvent(t *testing.T) {
+
+	event := &pb.TransactionEvent{
+		TransactionID: "tx1",
+		Type:          pb.TransactionEventType_EVENT_STATE_REPORTED,
+		Sequence:      4,
+		Detail: &pb.TransactionEvent_State{
+			State: &pb.TransactionState{State: "Canceled", Sequence: 4},
+		},
+	}
+
+	expected := proto.Clone(event).(*pb.TransactionEvent)
+	expected.EventName = "StateReported"
+
+	normalizeEvent(event)
+
+	// Typed event only gets its legacy name
+	if !proto.Equal(event, expected) {
+		t.Errorf("Unexpected event %v", event)
+	}
+
+	// Typed detail is never replaced by payload
+	event = &pb.TransactionEvent{
+		Type:    pb.TransactionEventType_EVENT_COMMAND_RECEIVED,
+		Payload: "other",
+		Detail: &pb.TransactionEvent_Receipt{
+			Receipt: &pb.CommandReceipt{CommandID: "cmd1"},
+		},
+	}
+
+	normalizeEvent(event)
+
+	if event.EventName != "CommandReceived" || event.GetReceipt().GetCommandID() != "cmd1" {
+		t.Errorf("Unexpected event %v", event)
+	}
+}
@@ -0,0 +1,78 @@
+package commander
+
+import (
+	pb "twist-commander/pb"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	log "github.com/sirupsen/logrus"
+)
+
+// Outcomes of confirming or canceling a transaction, as reported by runner
+const (
+	OutcomeConfirmed         = "Confirmed"
+	OutcomeCanceled          = "Canceled"
+	OutcomeTimeout           = "Timeout"
+	OutcomeHeuristicMixed    = "HeuristicMixed"
+	OutcomeHeuristicCommit   = "HeuristicCommit"
+	OutcomeHeuristicRollback = "HeuristicRollback"
+)
+
+// TransactionOutcome is the final result of a transaction. Detail carries
+// payload of event, which describes results of tasks for heuristic outcomes.
+type TransactionOutcome struct {
+	Name   string
+	Detail string
+}
+
+func createOutcome(event *pb.TransactionEvent) *TransactionOutcome {
+
+	outcome := &TransactionOutcome{
+		Name:   event.EventName,
+		Detail: event.Payload,
+	}
+
+	// Typed runners describe outcome in detail of event instead of payload
+	if outcome.Detail == "" {
+		var detail proto.Message
+		if results := event.GetTaskResults(); results != nil {
+			detail = results
+		} else if failure := event.GetFailure(); failure != nil {
+			detail = failure
+		}
+
+		if detail != nil {
+			if s, err := (&jsonpb.Marshaler{}).MarshalToString(detail); err == nil {
+				outcome.Detail = s
+			}
+		}
+	}
+
+	return outcome
+}
+
+// IsHeuristic returns true if some participants made decision on their own,
+// so the result cannot be treated as a clean success or failure.
+func (outcome *TransactionOutcome) IsHeuristic() bool {
+
+	switch outcome.Name {
+	case OutcomeHeuristicMixed, OutcomeHeuristicCommit, OutcomeHeuristicRollback:
+		return true
+	}
+
+	return false
+}
+
+// reportOutcome makes heuristic outcomes visible to operators
+func reportOutcome(transactionID string, outcome *TransactionOutcome) {
+
+	if outcome == nil || !outcome.IsHeuristic() {
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"transaction": transactionID,
+		"outcome":     outcome.Name,
+		"detail":      outcome.Detail,
+	}).Warn("Transaction ended with heuristic outcome")
+}
@@ -0,0 +1,93 @@
+package commander
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	app "twist-commander/app/interface"
+	"twist-commander/app/store"
+	pb "twist-commander/pb"
+)
+
+func (service *Service) recordCreated(transactionID string, mode string) {
+
+	err := service.app.GetStore().Create(&app.Transaction{
+		TransactionID: transactionID,
+		Mode:          mode,
+		InstanceID:    service.app.GetInstanceID(),
+	})
+	if err != nil {
+		log.WithFields(log.Fields{
+			"transactionID": transactionID,
+			"error":         err,
+		}).Warn("Failed to record transaction")
+	}
+}
+
+// record updates history of transaction, which serves status queries and
+// auditing only, so failing to record never fails the request itself
+func (service *Service) record(transactionID string, fn func(*app.Transaction)) {
+
+	err := service.app.GetStore().Update(transactionID, fn)
+	if err == nil {
+		return
+	}
+
+	// Transaction was created before memory store was, or by another instance
+	if err == store.ErrNotFound {
+		log.WithFields(log.Fields{
+			"transactionID": transactionID,
+		}).Debug("Transaction is not recorded")
+
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"transactionID": transactionID,
+		"error":         err,
+	}).Warn("Failed to record transaction")
+}
+
+func (service *Service) recordTransition(transactionID string, state string, reason string) {
+	service.record(transactionID, func(tx *app.Transaction) {
+		store.ApplyTransition(tx, state, reason)
+	})
+}
+
+// recordResult records outcome reported by runner, or the reason why there is
+// none
+func (service *Service) recordResult(transactionID string, outcome *TransactionOutcome, err error) {
+
+	if outcome != nil {
+		service.record(transactionID, func(tx *app.Transaction) {
+			store.ApplyOutcome(tx, outcome.Name, outcome.Detail)
+		})
+
+		return
+	}
+
+	if err != nil {
+		service.recordTransition(transactionID, store.StateFailed, err.Error())
+	}
+}
+
+func (service *Service) recordTasks(transactionID string, tasks []*pb.TransactionTask, replace bool) {
+	service.record(transactionID, func(tx *app.Transaction) {
+		if replace {
+			tx.Tasks = tasks
+			return
+		}
+
+		tx.Tasks = append(tx.Tasks, tasks...)
+	})
+}
+
+func (service *Service) recordTaskRemoved(transactionID string, taskID string) {
+	service.record(transactionID, func(tx *app.Transaction) {
+		for i, task := range tx.Tasks {
+			if task.Id == taskID {
+				tx.Tasks = append(tx.Tasks[:i], tx.Tasks[i+1:]...)
+				return
+			}
+		}
+	})
+}
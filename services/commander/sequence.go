@@ -0,0 +1,97 @@
+package commander
+
+import (
+	"sync"
+	"time"
+)
+
+// SequenceTracker remembers the last event sequence of every transaction, so
+// agents of later requests can tell that events were missed in between
+type SequenceTracker struct {
+	mutex   sync.Mutex
+	entries map[string]*sequenceEntry
+	ttl     time.Duration
+	sweptAt time.Time
+}
+
+type sequenceEntry struct {
+	sequence  uint64
+	updatedAt time.Time
+}
+
+func CreateSequenceTracker(ttl time.Duration) *SequenceTracker {
+
+	if ttl <= 0 {
+		ttl = time.Hour
+	}
+
+	return &SequenceTracker{
+		entries: make(map[string]*sequenceEntry),
+		ttl:     ttl,
+		sweptAt: time.Now(),
+	}
+}
+
+// Observe records sequence of event and returns the highest one seen before
+func (st *SequenceTracker) Observe(transactionID string, sequence uint64) uint64 {
+
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	now := time.Now()
+	st.sweep(now)
+
+	entry, ok := st.entries[transactionID]
+	if !ok {
+		entry = &sequenceEntry{}
+		st.entries[transactionID] = entry
+	}
+
+	last := entry.sequence
+	if sequence > entry.sequence {
+		entry.sequence = sequence
+	}
+
+	entry.updatedAt = now
+
+	return last
+}
+
+// Last returns the highest sequence seen of transaction, zero if none
+func (st *SequenceTracker) Last(transactionID string) uint64 {
+
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	entry, ok := st.entries[transactionID]
+	if !ok {
+		return 0
+	}
+
+	return entry.sequence
+}
+
+// Forget drops transaction which has finished
+func (st *SequenceTracker) Forget(transactionID string) {
+
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	delete(st.entries, transactionID)
+}
+
+// sweep drops transactions which were quiet for too long
+func (st *SequenceTracker) sweep(now time.Time) {
+
+	if now.Sub(st.sweptAt) < st.ttl/2 {
+		return
+	}
+
+	st.sweptAt = now
+
+	for transactionID, entry := range st.entries {
+		if now.Sub(entry.updatedAt) > st.ttl {
+			delete(st.entries, transactionID)
+		}
+	}
+}
@@ -1,25 +1,24 @@
 package commander
 
 import (
-	"sync"
-	"time"
-
-	"github.com/gogo/protobuf/proto"
-	"github.com/nats-io/nats.go"
 	uuid "github.com/satori/go.uuid"
 	log "github.com/sirupsen/logrus"
-
 	"github.com/spf13/viper"
+
 	"golang.org/x/net/context"
-	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	app "twist-commander/app/interface"
+	"twist-commander/app/store"
 	pb "twist-commander/pb"
 )
 
 type Service struct {
 	app       app.AppImpl
 	commander *Commander
+	simulator *Simulator
+	validator *TaskValidator
 }
 
 func CreateService(a app.AppImpl) *Service {
@@ -28,6 +27,8 @@ func CreateService(a app.AppImpl) *Service {
 	service := &Service{
 		app:       a,
 		commander: CreateCommander(a),
+		simulator: CreateSimulator(viper.GetDuration("simulator.ttl")),
+		validator: CreateTaskValidator(viper.GetStringSlice("task.action_types")),
 	}
 
 	return service
@@ -35,6 +36,12 @@ func CreateService(a app.AppImpl) *Service {
 
 func (service *Service) CreateTransaction(ctx context.Context, in *pb.CreateTransactionRequest) (*pb.CreateTransactionReply, error) {
 
+	release, err := service.app.GetAdmission().Acquire(ctx, "create")
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	mode := in.Mode
 	if in.Mode == "" {
 		mode = "sync"
@@ -43,47 +50,37 @@ func (service *Service) CreateTransaction(ctx context.Context, in *pb.CreateTran
 	tid := uuid.NewV1()
 	transactionID := tid.String()
 
-	// Waiting for response from queue
-	wg := sync.WaitGroup{}
-	wg.Add(1)
+	// Simulated transaction never goes to supervisor and runner
+	if mode == "simulate" {
+		service.simulator.CreateTransaction(transactionID)
 
-	// Listening to queue
-	sb := service.app.GetSignalBus()
-	sub, err := sb.Watch("twist.transaction."+transactionID+".eventEmitted", func(msg *nats.Msg) {
-		var event pb.TransactionEvent
-		err := proto.Unmarshal(msg.Data, &event)
-		if err != nil {
-			return
-		}
+		log.WithFields(log.Fields{
+			"mode": mode,
+		}).Info("Created transation: ", transactionID)
 
-		// Got message that transaction was assigned to runner already
-		if event.EventName == "Assigned" && event.TransactionID == transactionID {
-			wg.Done()
-			return
-		}
-	})
-	if err != nil {
-		log.Error("did not connect: ", err)
 		return &pb.CreateTransactionReply{
-			Success: false,
+			Success:       true,
+			TransactionID: transactionID,
 		}, nil
 	}
-	defer sub.Unsubscribe()
 
-	// Set up a connection to supervisor.
-	address := viper.GetString("supervisor.host")
-	conn, err := grpc.Dial(address, grpc.WithInsecure(), grpc.WithBlock())
+	// Listening to queue
+	agent, err := service.commander.agentMgr.CreateAgent(transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = agent.OpenEventChannel()
 	if err != nil {
+		agent.CloseEventChannel()
 		log.Error("did not connect: ", err)
 		return &pb.CreateTransactionReply{
 			Success: false,
 		}, nil
 	}
-	defer conn.Close()
+	defer agent.CloseEventChannel()
 
-	// Preparing context
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	defer cancel()
+	service.recordCreated(transactionID, mode)
 
 	req := &pb.PrepareTransactionRequest{
 		TransactionID: transactionID,
@@ -91,15 +88,23 @@ func (service *Service) CreateTransaction(ctx context.Context, in *pb.CreateTran
 	}
 
 	// Prepare transaction
-	res, err := pb.NewSupervisorClient(conn).PrepareTransaction(ctx, req)
+	res, err := service.app.GetSupervisorClient().PrepareTransaction(ctx, req)
 	if err != nil {
 		log.Error(err)
+		service.recordTransition(transactionID, store.StateFailed, err.Error())
+
+		// No supervisor is available
+		if status.Code(err) == codes.Unavailable {
+			return nil, err
+		}
+
 		return &pb.CreateTransactionReply{
 			Success: false,
 		}, nil
 	}
 
 	if res.Success == false {
+		service.recordTransition(transactionID, store.StateFailed, "Supervisor did not prepare transaction")
 		return &pb.CreateTransactionReply{
 			Success: false,
 		}, nil
@@ -110,12 +115,39 @@ func (service *Service) CreateTransaction(ctx context.Context, in *pb.CreateTran
 	}).Info("Created transation: ", res.TransactionID)
 
 	// Wait transaction event that is ready
-	wg.Wait()
+	ready := false
+
+READY:
+	for {
+		select {
+		case event, ok := <-agent.EventChannel:
+
+			// Agent was closed
+			if !ok {
+				break READY
+			}
+
+			// Got message that transaction was assigned to runner already
+			if event.Type == pb.TransactionEventType_EVENT_ASSIGNED && event.TransactionID == transactionID {
+				ready = true
+				break READY
+			}
+		}
+	}
+
+	if ready == false {
+		service.recordTransition(transactionID, store.StateFailed, "Transaction was not assigned to runner")
+		return &pb.CreateTransactionReply{
+			Success: false,
+		}, nil
+	}
 
 	log.WithFields(log.Fields{
 		"transaction": res.TransactionID,
 	}).Info("Transaction is ready")
 
+	service.recordTransition(transactionID, store.StateAssigned, "")
+
 	return &pb.CreateTransactionReply{
 		Success:       true,
 		TransactionID: res.TransactionID,
@@ -124,24 +156,114 @@ func (service *Service) CreateTransaction(ctx context.Context, in *pb.CreateTran
 
 func (service *Service) ConfirmTransaction(ctx context.Context, in *pb.ConfirmTransactionRequest) (*pb.ConfirmTransactionReply, error) {
 
-	err := service.commander.ConfirmTransaction(in.TransactionID, in)
+	release, err := service.app.GetAdmission().Acquire(ctx, "confirm")
 	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// Reject invalid tasks before sending them to runner
+	violations := service.validator.ValidateTasks(in.Tasks)
+	if len(violations) > 0 {
 		return &pb.ConfirmTransactionReply{
 			Success:       false,
 			TransactionID: in.TransactionID,
+			Violations:    violations,
 		}, nil
 	}
 
+	assignTaskIDs(in.Tasks)
+
+	if service.simulator.Has(in.TransactionID) {
+		transcript, err := service.simulator.Confirm(in.TransactionID, in.Tasks)
+		if err != nil {
+			return &pb.ConfirmTransactionReply{
+				Success:       false,
+				TransactionID: in.TransactionID,
+			}, nil
+		}
+
+		return &pb.ConfirmTransactionReply{
+			Success:       true,
+			TransactionID: in.TransactionID,
+			Transcript:    transcript,
+			Outcome:       OutcomeConfirmed,
+		}, nil
+	}
+
+	// Tasks which come with confirmation are registered by runner as well
+	if len(in.Tasks) > 0 {
+		service.recordTasks(in.TransactionID, in.Tasks, false)
+	}
+
+	service.recordTransition(in.TransactionID, store.StateConfirming, "")
+
+	outcome, err := service.commander.ConfirmTransaction(in.TransactionID, in)
+	reportOutcome(in.TransactionID, outcome)
+	service.recordResult(in.TransactionID, outcome, err)
+	if err != nil {
+
+		// Signal server is not available, or transaction ID is invalid
+		if isStatusError(err) {
+			return nil, err
+		}
+
+		reply := &pb.ConfirmTransactionReply{
+			Success:       false,
+			TransactionID: in.TransactionID,
+		}
+
+		if outcome != nil {
+			reply.Outcome = outcome.Name
+			reply.OutcomeDetail = outcome.Detail
+		}
+
+		return reply, nil
+	}
+
 	return &pb.ConfirmTransactionReply{
 		Success:       true,
 		TransactionID: in.TransactionID,
+		Outcome:       outcome.Name,
+		OutcomeDetail: outcome.Detail,
 	}, nil
 }
 
 func (service *Service) RegisterTasks(ctx context.Context, in *pb.RegisterTasksRequest) (*pb.RegisterTasksReply, error) {
 
-	err := service.commander.RegisterTasks(in.TransactionID, in)
+	release, err := service.app.GetAdmission().Acquire(ctx, "tasks")
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// Reject invalid tasks before sending them to runner
+	violations := service.validator.ValidateTasks(in.Tasks)
+	if len(violations) > 0 {
+		return &pb.RegisterTasksReply{
+			Success:       false,
+			TransactionID: in.TransactionID,
+			Violations:    violations,
+		}, nil
+	}
+
+	assignTaskIDs(in.Tasks)
+
+	if service.simulator.Has(in.TransactionID) {
+		err = service.simulator.RegisterTasks(in.TransactionID, in.Tasks)
+	} else {
+		err = service.commander.RegisterTasks(in.TransactionID, in)
+		if err == nil {
+			service.recordTasks(in.TransactionID, in.Tasks, false)
+		}
+	}
 	if err != nil {
+
+		// Signal server is not available, or transaction ID is invalid
+		if isStatusError(err) {
+			return nil, err
+		}
+
 		return &pb.RegisterTasksReply{
 			Success:       false,
 			TransactionID: in.TransactionID,
@@ -154,18 +276,214 @@ func (service *Service) RegisterTasks(ctx context.Context, in *pb.RegisterTasksR
 	}, nil
 }
 
+func (service *Service) ListTasks(ctx context.Context, in *pb.ListTasksRequest) (*pb.ListTasksReply, error) {
+
+	release, err := service.app.GetAdmission().Acquire(ctx, "tasks")
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	var tasks []*pb.TransactionTask
+	if service.simulator.Has(in.TransactionID) {
+		tasks, err = service.simulator.ListTasks(in.TransactionID)
+	} else {
+		tasks, err = service.commander.ListTasks(in.TransactionID, in)
+	}
+	if err != nil {
+
+		// Signal server is not available, or transaction ID is invalid
+		if isStatusError(err) {
+			return nil, err
+		}
+
+		return &pb.ListTasksReply{
+			Success:       false,
+			TransactionID: in.TransactionID,
+		}, nil
+	}
+
+	return &pb.ListTasksReply{
+		Success:       true,
+		TransactionID: in.TransactionID,
+		Tasks:         tasks,
+	}, nil
+}
+
+func (service *Service) ReplaceTasks(ctx context.Context, in *pb.ReplaceTasksRequest) (*pb.ReplaceTasksReply, error) {
+
+	release, err := service.app.GetAdmission().Acquire(ctx, "tasks")
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// Reject invalid tasks before sending them to runner
+	violations := service.validator.ValidateTasks(in.Tasks)
+	if len(violations) > 0 {
+		return &pb.ReplaceTasksReply{
+			Success:       false,
+			TransactionID: in.TransactionID,
+			Violations:    violations,
+		}, nil
+	}
+
+	assignTaskIDs(in.Tasks)
+
+	if service.simulator.Has(in.TransactionID) {
+		err = service.simulator.ReplaceTasks(in.TransactionID, in.Tasks)
+	} else {
+		err = service.commander.ReplaceTasks(in.TransactionID, in)
+		if err == nil {
+			service.recordTasks(in.TransactionID, in.Tasks, true)
+		}
+	}
+	if err != nil {
+
+		// Signal server is not available, or transaction ID is invalid
+		if isStatusError(err) {
+			return nil, err
+		}
+
+		return &pb.ReplaceTasksReply{
+			Success:       false,
+			TransactionID: in.TransactionID,
+		}, nil
+	}
+
+	return &pb.ReplaceTasksReply{
+		Success:       true,
+		TransactionID: in.TransactionID,
+	}, nil
+}
+
+func (service *Service) RemoveTask(ctx context.Context, in *pb.RemoveTaskRequest) (*pb.RemoveTaskReply, error) {
+
+	release, err := service.app.GetAdmission().Acquire(ctx, "tasks")
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if service.simulator.Has(in.TransactionID) {
+		err = service.simulator.RemoveTask(in.TransactionID, in.TaskID)
+	} else {
+		err = service.commander.RemoveTask(in.TransactionID, in)
+		if err == nil {
+			service.recordTaskRemoved(in.TransactionID, in.TaskID)
+		}
+	}
+	if err != nil {
+
+		// Signal server is not available, or task does not exist
+		if isStatusError(err) {
+			return nil, err
+		}
+
+		return &pb.RemoveTaskReply{
+			Success:       false,
+			TransactionID: in.TransactionID,
+		}, nil
+	}
+
+	return &pb.RemoveTaskReply{
+		Success:       true,
+		TransactionID: in.TransactionID,
+	}, nil
+}
+
+// isStatusError reports whether failure is returned as error rather than as
+// unsuccessful reply, so that HTTP gateway responds with matching status
+func isStatusError(err error) bool {
+
+	switch status.Code(err) {
+	case codes.Unavailable, codes.InvalidArgument, codes.NotFound:
+		return true
+	}
+
+	return false
+}
+
+// Close makes all requests which are waiting for runner give up
+func (service *Service) Close() {
+	service.commander.Close()
+}
+
+func (service *Service) ValidateTasks(ctx context.Context, in *pb.ValidateTasksRequest) (*pb.ValidateTasksReply, error) {
+
+	violations := service.validator.ValidateTasks(in.Tasks)
+
+	return &pb.ValidateTasksReply{
+		Success:    len(violations) == 0,
+		Violations: violations,
+	}, nil
+}
+
 func (service *Service) CancelTransaction(ctx context.Context, in *pb.CancelTransactionRequest) (*pb.CancelTransactionReply, error) {
 
-	err := service.commander.CancelTransaction(in.TransactionID, in)
+	release, err := service.app.GetAdmission().Acquire(ctx, "cancel")
 	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if service.simulator.Has(in.TransactionID) {
+
+		transcript, err := service.simulator.Cancel(in.TransactionID)
+		if err != nil {
+			return &pb.CancelTransactionReply{
+				Success:       false,
+				TransactionID: in.TransactionID,
+			}, nil
+		}
+
 		return &pb.CancelTransactionReply{
-			Success:       false,
+			Success:       true,
 			TransactionID: in.TransactionID,
+			Transcript:    transcript,
+			Outcome:       OutcomeCanceled,
 		}, nil
 	}
 
+	service.recordTransition(in.TransactionID, store.StateCanceling, "")
+
+	outcome, err := service.commander.CancelTransaction(in.TransactionID, in)
+	reportOutcome(in.TransactionID, outcome)
+	service.recordResult(in.TransactionID, outcome, err)
+	if err != nil {
+
+		// Signal server is not available, or transaction ID is invalid
+		if isStatusError(err) {
+			return nil, err
+		}
+
+		reply := &pb.CancelTransactionReply{
+			Success:       false,
+			TransactionID: in.TransactionID,
+		}
+
+		if outcome != nil {
+			reply.Outcome = outcome.Name
+			reply.OutcomeDetail = outcome.Detail
+		}
+
+		return reply, nil
+	}
+
 	return &pb.CancelTransactionReply{
 		Success:       true,
 		TransactionID: in.TransactionID,
+		Outcome:       outcome.Name,
+		OutcomeDetail: outcome.Detail,
 	}, nil
 }
+
+// assignTaskIDs gives every task without an ID a unique one, so that it can be
+// addressed by RemoveTask later
+func assignTaskIDs(tasks []*pb.TransactionTask) {
+	for _, task := range tasks {
+		if task.Id == "" {
+			task.Id = uuid.NewV4().String()
+		}
+	}
+}
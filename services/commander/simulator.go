@@ -0,0 +1,234 @@
+package commander
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "twist-commander/pb"
+)
+
+// Simulator keeps transactions which were created in "simulate" mode. Their
+// whole lifecycle is handled in place and participants are never invoked.
+// Simulated transactions only exist in the instance which created them, and
+// are dropped once they were not touched for a while.
+type Simulator struct {
+	mutex        sync.Mutex
+	transactions map[string]*SimulatedTransaction
+	ttl          time.Duration
+	sweptAt      time.Time
+}
+
+type SimulatedTransaction struct {
+	TransactionID string
+	Tasks         []*pb.TransactionTask
+	updatedAt     time.Time
+}
+
+func CreateSimulator(ttl time.Duration) *Simulator {
+
+	if ttl <= 0 {
+		ttl = time.Hour
+	}
+
+	return &Simulator{
+		transactions: make(map[string]*SimulatedTransaction),
+		ttl:          ttl,
+		sweptAt:      time.Now(),
+	}
+}
+
+func (sim *Simulator) CreateTransaction(transactionID string) {
+
+	sim.mutex.Lock()
+	defer sim.mutex.Unlock()
+
+	now := time.Now()
+	sim.sweep(now)
+
+	sim.transactions[transactionID] = &SimulatedTransaction{
+		TransactionID: transactionID,
+		Tasks:         make([]*pb.TransactionTask, 0),
+		updatedAt:     now,
+	}
+}
+
+func (sim *Simulator) Has(transactionID string) bool {
+
+	sim.mutex.Lock()
+	defer sim.mutex.Unlock()
+
+	_, ok := sim.get(transactionID)
+
+	return ok
+}
+
+// get returns transaction which has not expired yet and keeps it alive
+func (sim *Simulator) get(transactionID string) (*SimulatedTransaction, bool) {
+
+	now := time.Now()
+	sim.sweep(now)
+
+	transaction, ok := sim.transactions[transactionID]
+	if !ok {
+		return nil, false
+	}
+
+	transaction.updatedAt = now
+
+	return transaction, true
+}
+
+// sweep drops transactions which were not confirmed or canceled in time
+func (sim *Simulator) sweep(now time.Time) {
+
+	if now.Sub(sim.sweptAt) < sim.ttl/2 {
+		return
+	}
+
+	sim.sweptAt = now
+
+	for transactionID, transaction := range sim.transactions {
+		if now.Sub(transaction.updatedAt) > sim.ttl {
+			delete(sim.transactions, transactionID)
+		}
+	}
+}
+
+func (sim *Simulator) RegisterTasks(transactionID string, tasks []*pb.TransactionTask) error {
+
+	sim.mutex.Lock()
+	defer sim.mutex.Unlock()
+
+	transaction, ok := sim.get(transactionID)
+	if !ok {
+		return errors.New("Transaction not found")
+	}
+
+	transaction.Tasks = append(transaction.Tasks, tasks...)
+
+	return nil
+}
+
+func (sim *Simulator) ReplaceTasks(transactionID string, tasks []*pb.TransactionTask) error {
+
+	sim.mutex.Lock()
+	defer sim.mutex.Unlock()
+
+	transaction, ok := sim.get(transactionID)
+	if !ok {
+		return errors.New("Transaction not found")
+	}
+
+	transaction.Tasks = append(make([]*pb.TransactionTask, 0, len(tasks)), tasks...)
+
+	return nil
+}
+
+func (sim *Simulator) ListTasks(transactionID string) ([]*pb.TransactionTask, error) {
+
+	sim.mutex.Lock()
+	defer sim.mutex.Unlock()
+
+	transaction, ok := sim.get(transactionID)
+	if !ok {
+		return nil, errors.New("Transaction not found")
+	}
+
+	return append(make([]*pb.TransactionTask, 0, len(transaction.Tasks)), transaction.Tasks...), nil
+}
+
+func (sim *Simulator) RemoveTask(transactionID string, taskID string) error {
+
+	sim.mutex.Lock()
+	defer sim.mutex.Unlock()
+
+	transaction, ok := sim.get(transactionID)
+	if !ok {
+		return errors.New("Transaction not found")
+	}
+
+	for i, task := range transaction.Tasks {
+		if task.Id == taskID {
+			transaction.Tasks = append(transaction.Tasks[:i], transaction.Tasks[i+1:]...)
+			return nil
+		}
+	}
+
+	return status.Error(codes.NotFound, "Task not found")
+}
+
+// Confirm finishes transaction and returns confirm calls of all tasks in order
+func (sim *Simulator) Confirm(transactionID string, tasks []*pb.TransactionTask) ([]*pb.SimulatedCall, error) {
+
+	sim.mutex.Lock()
+	defer sim.mutex.Unlock()
+
+	transaction, ok := sim.get(transactionID)
+	if !ok {
+		return nil, errors.New("Transaction not found")
+	}
+
+	delete(sim.transactions, transactionID)
+
+	transaction.Tasks = append(transaction.Tasks, tasks...)
+
+	transcript := make([]*pb.SimulatedCall, 0, len(transaction.Tasks))
+	for _, task := range transaction.Tasks {
+		transcript = append(transcript, renderCall(int32(len(transcript)+1), task.Id, "confirm", task.Confirm))
+	}
+
+	return transcript, nil
+}
+
+// Cancel finishes transaction and returns cancel calls of all tasks in order
+func (sim *Simulator) Cancel(transactionID string) ([]*pb.SimulatedCall, error) {
+
+	sim.mutex.Lock()
+	defer sim.mutex.Unlock()
+
+	transaction, ok := sim.get(transactionID)
+	if !ok {
+		return nil, errors.New("Transaction not found")
+	}
+
+	delete(sim.transactions, transactionID)
+
+	transcript := make([]*pb.SimulatedCall, 0, len(transaction.Tasks))
+	for _, task := range transaction.Tasks {
+		transcript = append(transcript, renderCall(int32(len(transcript)+1), task.Id, "cancel", task.Cancel))
+	}
+
+	return transcript, nil
+}
+
+// renderCall prepares the HTTP request from action as it was defined, headers
+// which runner adds on its own are not included
+func renderCall(sequence int32, taskID string, name string, action *pb.TransactionTaskAction) *pb.SimulatedCall {
+
+	call := &pb.SimulatedCall{
+		Sequence: sequence,
+		TaskID:   taskID,
+		Action:   name,
+		Headers:  make(map[string]string),
+	}
+
+	if action == nil {
+		return call
+	}
+
+	call.Method = strings.ToUpper(action.Method)
+	call.Uri = action.Uri
+	call.Payload = action.Payload
+
+	for key, value := range action.Headers {
+		call.Headers[http.CanonicalHeaderKey(key)] = value
+	}
+
+	return call
+}
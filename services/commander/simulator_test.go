@@ -0,0 +1,59 @@
+package commander
+
+import (
+	"testing"
+	"time"
+
+	pb "twist-commander/pb"
+)
+
+func TestSimulatorConfirmRendersCalls(t *testing.T) {
+
+	sim := CreateSimulator(time.Hour)
+	sim.CreateTransaction("tx")
+
+	task := validTask("a")
+	task.Confirm.Payload = "{}"
+
+	if err := sim.RegisterTasks("tx", []*pb.TransactionTask{task}); err != nil {
+		t.Fatal(err)
+	}
+
+	transcript, err := sim.Confirm("tx", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(transcript) != 1 || transcript[0].Method != "POST" || transcript[0].Action != "confirm" {
+		t.Fatalf("unexpected transcript %v", transcript)
+	}
+
+	// Headers which were not defined are not invented
+	if len(transcript[0].Headers) != 0 {
+		t.Fatalf("unexpected headers %v", transcript[0].Headers)
+	}
+
+	if sim.Has("tx") {
+		t.Fatal("confirmed transaction was kept")
+	}
+}
+
+func TestSimulatorExpiresIdleTransactions(t *testing.T) {
+
+	sim := CreateSimulator(20 * time.Millisecond)
+	sim.CreateTransaction("idle")
+	sim.CreateTransaction("busy")
+
+	for i := 0; i < 4; i++ {
+		time.Sleep(10 * time.Millisecond)
+		sim.Has("busy")
+	}
+
+	if sim.Has("idle") {
+		t.Fatal("idle transaction was not dropped")
+	}
+
+	if !sim.Has("busy") {
+		t.Fatal("transaction in use was dropped")
+	}
+}
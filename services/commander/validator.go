@@ -0,0 +1,111 @@
+package commander
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	pb "twist-commander/pb"
+)
+
+var supportedMethods = map[string]bool{
+	"GET":     true,
+	"POST":    true,
+	"PUT":     true,
+	"PATCH":   true,
+	"DELETE":  true,
+	"HEAD":    true,
+	"OPTIONS": true,
+}
+
+// TaskValidator checks task definitions before they are sent to runner
+type TaskValidator struct {
+
+	// Empty means that runner decides which action types it supports
+	actionTypes map[string]bool
+}
+
+func CreateTaskValidator(actionTypes []string) *TaskValidator {
+
+	v := &TaskValidator{
+		actionTypes: make(map[string]bool),
+	}
+
+	for _, t := range actionTypes {
+		v.actionTypes[t] = true
+	}
+
+	return v
+}
+
+// ValidateTasks checks task definitions and returns every problem found. An
+// empty result means tasks are safe to be registered.
+func (v *TaskValidator) ValidateTasks(tasks []*pb.TransactionTask) []*pb.TaskViolation {
+
+	violations := make([]*pb.TaskViolation, 0)
+	ids := make(map[string]bool)
+
+	for i, task := range tasks {
+
+		index := int32(i)
+
+		if task == nil {
+			violations = append(violations, createViolation(index, fmt.Sprintf("tasks[%d]", i), "task is empty"))
+			continue
+		}
+
+		// Task ID must be unique in the same transaction
+		if task.Id != "" {
+			if ids[task.Id] {
+				violations = append(violations, createViolation(index, fmt.Sprintf("tasks[%d].id", i), "duplicate task ID \""+task.Id+"\""))
+			}
+
+			ids[task.Id] = true
+		}
+
+		violations = append(violations, v.validateAction(index, fmt.Sprintf("tasks[%d].actions.confirm", i), task.Confirm)...)
+		violations = append(violations, v.validateAction(index, fmt.Sprintf("tasks[%d].actions.cancel", i), task.Cancel)...)
+	}
+
+	return violations
+}
+
+func (v *TaskValidator) validateAction(index int32, field string, action *pb.TransactionTaskAction) []*pb.TaskViolation {
+
+	violations := make([]*pb.TaskViolation, 0)
+
+	if action == nil {
+		return append(violations, createViolation(index, field, "action is required"))
+	}
+
+	if action.Type == "" {
+		violations = append(violations, createViolation(index, field+".type", "action type is required"))
+	} else if len(v.actionTypes) > 0 && !v.actionTypes[action.Type] {
+		violations = append(violations, createViolation(index, field+".type", "unknown action type \""+action.Type+"\""))
+	}
+
+	if action.Method == "" {
+		violations = append(violations, createViolation(index, field+".method", "method is required"))
+	} else if !supportedMethods[strings.ToUpper(action.Method)] {
+		violations = append(violations, createViolation(index, field+".method", "invalid HTTP method \""+action.Method+"\""))
+	}
+
+	if action.Uri == "" {
+		violations = append(violations, createViolation(index, field+".uri", "URI is required"))
+	} else {
+		u, err := url.ParseRequestURI(action.Uri)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			violations = append(violations, createViolation(index, field+".uri", "invalid URI \""+action.Uri+"\""))
+		}
+	}
+
+	return violations
+}
+
+func createViolation(index int32, field string, description string) *pb.TaskViolation {
+	return &pb.TaskViolation{
+		TaskIndex:   index,
+		Field:       field,
+		Description: description,
+	}
+}
@@ -0,0 +1,98 @@
+package commander
+
+import (
+	"testing"
+
+	pb "twist-commander/pb"
+)
+
+func validTask(id string) *pb.TransactionTask {
+	return &pb.TransactionTask{
+		Id: id,
+		Confirm: &pb.TransactionTaskAction{
+			Type:   "rest",
+			Method: "post",
+			Uri:    "http://participant/confirm",
+		},
+		Cancel: &pb.TransactionTaskAction{
+			Type:   "rest",
+			Method: "DELETE",
+			Uri:    "https://participant/cancel",
+		},
+	}
+}
+
+func fields(violations []*pb.TaskViolation) map[string]bool {
+
+	result := make(map[string]bool)
+	for _, v := range violations {
+		result[v.Field] = true
+	}
+
+	return result
+}
+
+func TestValidateTasksAcceptsValidTasks(t *testing.T) {
+
+	v := CreateTaskValidator(nil)
+
+	violations := v.ValidateTasks([]*pb.TransactionTask{validTask("a"), validTask("")})
+	if len(violations) != 0 {
+		t.Fatalf("expected no violations, got %v", violations)
+	}
+}
+
+func TestValidateTasksReportsEveryProblem(t *testing.T) {
+
+	v := CreateTaskValidator(nil)
+
+	broken := validTask("a")
+	broken.Confirm.Method = "FETCH"
+	broken.Confirm.Uri = "ftp://participant"
+	broken.Cancel = nil
+
+	missing := &pb.TransactionTask{
+		Id:      "b",
+		Confirm: &pb.TransactionTaskAction{},
+		Cancel:  validTask("").Cancel,
+	}
+
+	got := fields(v.ValidateTasks([]*pb.TransactionTask{broken, missing, validTask("a"), nil}))
+
+	expected := []string{
+		"tasks[0].actions.confirm.method",
+		"tasks[0].actions.confirm.uri",
+		"tasks[0].actions.cancel",
+		"tasks[1].actions.confirm.type",
+		"tasks[1].actions.confirm.method",
+		"tasks[1].actions.confirm.uri",
+		"tasks[2].id",
+		"tasks[3]",
+	}
+
+	for _, field := range expected {
+		if !got[field] {
+			t.Errorf("expected violation of %s", field)
+		}
+	}
+
+	if len(got) != len(expected) {
+		t.Errorf("expected %d violations, got %v", len(expected), got)
+	}
+}
+
+func TestValidateTasksActionTypes(t *testing.T) {
+
+	task := validTask("a")
+	task.Confirm.Type = "grpc"
+
+	// Any type is accepted unless supported ones were configured
+	if violations := CreateTaskValidator(nil).ValidateTasks([]*pb.TransactionTask{task}); len(violations) != 0 {
+		t.Fatalf("expected no violations, got %v", violations)
+	}
+
+	got := fields(CreateTaskValidator([]string{"rest"}).ValidateTasks([]*pb.TransactionTask{task}))
+	if !got["tasks[0].actions.confirm.type"] || len(got) != 1 {
+		t.Fatalf("expected violation of action type, got %v", got)
+	}
+}